@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tendant/simple-content-pipeline/internal/dedupe"
 	"github.com/tendant/simple-content-pipeline/internal/storage"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
@@ -68,8 +69,11 @@ func main() {
 
 	log.Printf("✓ HTTP client initialized")
 
-	// Initialize workflow runner
-	workflowRunner := workflows.NewWorkflowRunner()
+	// Initialize workflow runner. Standalone mode has no DBOS runtime (it
+	// talks to simple-content over plain HTTP, not through the Postgres-
+	// backed durable runner), so it runs workflows synchronously via
+	// WorkflowRunner.Run rather than RunAsync.
+	workflowRunner := workflows.NewWorkflowRunner(nil)
 
 	// Register workflows
 	thumbnailWorkflow := workflows.NewThumbnailWorkflow(contentReader, derivedWriter)
@@ -79,10 +83,13 @@ func main() {
 	// Create HTTP server
 	mux := http.NewServeMux()
 
-	// Create handler with dependencies
+	// Create handler with dependencies. There's no DBOS Postgres pool in
+	// standalone mode, so dedupe accounting is kept in-memory and resets on
+	// restart.
 	handler := &Handler{
 		workflowRunner: workflowRunner,
 		contentAPIURL:  contentAPIURL,
+		dedupeTracker:  dedupe.NewMemoryTracker(dedupe.Config{}),
 	}
 
 	// Register handlers
@@ -152,6 +159,7 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 type Handler struct {
 	workflowRunner *workflows.WorkflowRunner
 	contentAPIURL  string
+	dedupeTracker  dedupe.Tracker
 }
 
 // handleProcess handles the /v1/process endpoint
@@ -180,6 +188,17 @@ func (h *Handler) handleProcess(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Processing request: content_id=%s, job=%s, object_key=%s", req.ContentID, req.Job, req.ObjectKey)
 
+	// Record dedupe submission (track how many times this content has been submitted)
+	seenCount := 0
+	if h.dedupeTracker != nil {
+		count, err := h.dedupeTracker.Record(r.Context(), req.ContentID, req.Job, 1)
+		if err != nil {
+			log.Printf("Warning: Failed to record dedupe: %v (continuing anyway)", err)
+		} else {
+			seenCount = count
+		}
+	}
+
 	// Generate run ID
 	runID := uuid.New().String()
 
@@ -209,7 +228,7 @@ func (h *Handler) handleProcess(w http.ResponseWriter, r *http.Request) {
 	// Return response
 	resp := pipeline.ProcessResponse{
 		RunID:           runID,
-		DedupeSeenCount: 0, // TODO: Track dedupe count
+		DedupeSeenCount: seenCount,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
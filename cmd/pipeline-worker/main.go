@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -20,12 +21,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	simpleworkflow "github.com/tendant/simple-workflow"
 	"github.com/tendant/simple-content-pipeline/internal/dbosruntime"
+	"github.com/tendant/simple-content-pipeline/internal/deadletter"
 	"github.com/tendant/simple-content-pipeline/internal/dedupe"
+	"github.com/tendant/simple-content-pipeline/internal/detection"
 	"github.com/tendant/simple-content-pipeline/internal/executors"
 	"github.com/tendant/simple-content-pipeline/internal/handlers"
+	"github.com/tendant/simple-content-pipeline/internal/httpapi/resumable"
+	"github.com/tendant/simple-content-pipeline/internal/idle"
+	"github.com/tendant/simple-content-pipeline/internal/ocr"
+	"github.com/tendant/simple-content-pipeline/internal/progressstore"
 	"github.com/tendant/simple-content-pipeline/internal/storage"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline/retry"
+	"github.com/tendant/simple-content-pipeline/pkg/runlogs"
+	"github.com/tendant/simple-content/pkg/simplecontent"
 	"github.com/tendant/simple-content/pkg/simplecontent/presets"
 	_ "github.com/lib/pq"
 )
@@ -34,6 +44,15 @@ func main() {
 	// Load .env file if it exists (silently ignore if not found)
 	_ = godotenv.Load()
 
+	// Command-line flags
+	workflowsDirFlag := flag.String("workflows-dir", "", "directory of YAML/JSON workflow manifests to load at startup")
+	flag.Parse()
+
+	workflowsDir := *workflowsDirFlag
+	if workflowsDir == "" {
+		workflowsDir = os.Getenv("WORKFLOWS_DIR")
+	}
+
 	// Configuration from environment
 	httpAddr := os.Getenv("WORKER_HTTP_ADDR")
 	if httpAddr == "" {
@@ -51,9 +70,16 @@ func main() {
 	var derivedWriter interface {
 		HasDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int) (bool, error)
 		PutDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int, r io.Reader, meta map[string]string) (string, error)
+		StatByDigest(ctx context.Context, digest string) (bool, string, error)
+		RegisterDigest(ctx context.Context, digest string, derivedContentID string) error
 	}
 	var cleanup func()
 
+	// contentService is only set in embedded mode: the resumable upload
+	// subsystem below needs a streaming UploadContent call, which the
+	// simple-content HTTP client doesn't expose.
+	var contentService simplecontent.Service
+
 	if contentAPIURL != "" {
 		log.Printf("Using simple-content HTTP API at: %s", contentAPIURL)
 		contentReader = storage.NewHTTPContentReader(contentAPIURL)
@@ -67,6 +93,7 @@ func main() {
 		}
 		contentReader = storage.NewContentReader(svc)
 		derivedWriter = storage.NewDerivedWriter(svc)
+		contentService = svc
 		cleanup = cleanupFn
 	}
 	defer cleanup()
@@ -105,16 +132,52 @@ func main() {
 		log.Fatalf("Failed to initialize DBOS: %v", err)
 	}
 
+	// inflightTracker counts jobs in flight across the async handlers, the
+	// intent poller's executors, and the workflow runner, so shutdown can
+	// wait for them to drain instead of truncating an in-flight derived
+	// upload (see internal/idle).
+	inflightTracker := idle.NewTracker()
+
 	// Initialize workflow runner with DBOS support (registers workflows with DBOS)
 	workflowRunner := workflows.NewWorkflowRunner(dbosRuntime)
+	workflowRunner.SetIdleTracker(inflightTracker)
 
 	// Register workflows
 	thumbnailWorkflow := workflows.NewThumbnailWorkflow(contentReader, derivedWriter)
 	workflowRunner.Register(pipeline.JobThumbnail, thumbnailWorkflow)
 	log.Printf("✓ Registered workflow: %s for job: %s", thumbnailWorkflow.Name(), pipeline.JobThumbnail)
 
-	// Note: OCR and object detection are handled by the Python ML worker
-	// See python-worker/ directory for ML-based workflows
+	ocrLang := os.Getenv("OCR_LANGUAGE")
+	recognizer := ocr.NewTesseractRecognizer(ocrLang)
+	ocrWorkflow := workflows.NewOCRWorkflow(contentReader, derivedWriter, recognizer)
+	workflowRunner.Register(pipeline.JobOCR, ocrWorkflow)
+	log.Printf("✓ Registered workflow: %s for job: %s", ocrWorkflow.Name(), pipeline.JobOCR)
+
+	objectDetectionURL := os.Getenv("OBJECT_DETECTION_URL")
+	if objectDetectionURL == "" {
+		objectDetectionURL = "http://localhost:8500"
+	}
+	detector := detection.NewHTTPDetector(objectDetectionURL)
+	objectDetectionWorkflow := workflows.NewObjectDetectionWorkflow(contentReader, derivedWriter, detector)
+	// The object detection service is a slower, less reliable dependency than
+	// local I/O, so it gets a more patient policy than the runner's default
+	// (see WorkflowRunner.RegisterWithPolicy) instead of dead-lettering after
+	// the same few quick retries as thumbnail generation.
+	objectDetectionPolicy := retry.DefaultPolicy()
+	objectDetectionPolicy.MaxAttempts = 5
+	objectDetectionPolicy.MaxBackoff = 30 * time.Second
+	workflowRunner.RegisterWithPolicy(pipeline.JobObjectDetection, objectDetectionWorkflow, objectDetectionPolicy)
+	log.Printf("✓ Registered workflow: %s for job: %s", objectDetectionWorkflow.Name(), pipeline.JobObjectDetection)
+
+	// Manifest-driven workflows: operators can add pipelines declaratively,
+	// without recompiling the worker, by dropping a YAML/JSON spec in
+	// --workflows-dir or uploading one to POST /v1/workflows/manifests.
+	manifestRegistry := workflows.NewManifestRegistry(contentReader, derivedWriter)
+	if workflowsDir != "" {
+		if err := manifestRegistry.LoadDir(workflowRunner, workflowsDir); err != nil {
+			log.Fatalf("Failed to load workflow manifests from %s: %v", workflowsDir, err)
+		}
+	}
 
 	// Launch DBOS (must be done after workflow registration)
 	if err := dbosRuntime.Launch(); err != nil {
@@ -128,6 +191,7 @@ func main() {
 	log.Printf("  Concurrency: %d", dbosRuntime.Concurrency())
 
 	// Initialize simple-workflow intent poller
+	var poller *simpleworkflow.Poller
 	workflowDBURL := os.Getenv("WORKFLOW_DATABASE_URL")
 	if workflowDBURL == "" {
 		log.Printf("⚠ WORKFLOW_DATABASE_URL not set, intent poller disabled (using HTTP fallback)")
@@ -152,16 +216,12 @@ func main() {
 
 		// Create intent poller
 		supportedWorkflows := []string{"content.thumbnail.v1"}
-		poller := simpleworkflow.NewPoller(workflowDB, supportedWorkflows)
+		poller = simpleworkflow.NewPoller(workflowDB, supportedWorkflows)
 		poller.SetWorkerID("pipeline-worker-go")
 
-		// Initialize Prometheus metrics
-		metrics := simpleworkflow.NewPrometheusMetrics(nil) // nil = use default registry
-		poller.SetMetrics(metrics)
-		log.Printf("✓ Prometheus metrics enabled")
-
 		// Create and register thumbnail executor
 		thumbnailExecutor := executors.NewThumbnailExecutor(contentReader, derivedWriter)
+		thumbnailExecutor.SetIdleTracker(inflightTracker)
 		poller.RegisterExecutor("content.thumbnail.v1", thumbnailExecutor)
 
 		// Start poller in background
@@ -179,23 +239,236 @@ func main() {
 	}
 	defer dedupeDB.Close()
 
-	dedupeTracker, err := dedupe.NewTracker(dedupeDB)
+	dedupeTTL := 7 * 24 * time.Hour
+	if ttlStr := os.Getenv("DEDUPE_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil {
+			dedupeTTL = parsed
+		} else {
+			log.Printf("Warning: Invalid DEDUPE_TTL value '%s', using default: %s", ttlStr, dedupeTTL)
+		}
+	}
+
+	dedupeSweepInterval := time.Hour
+	if intervalStr := os.Getenv("DEDUPE_SWEEP_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil {
+			dedupeSweepInterval = parsed
+		} else {
+			log.Printf("Warning: Invalid DEDUPE_SWEEP_INTERVAL value '%s', using default: %s", intervalStr, dedupeSweepInterval)
+		}
+	}
+
+	suppressionWindow := time.Duration(0)
+	if windowStr := os.Getenv("DEDUPE_SUPPRESSION_WINDOW"); windowStr != "" {
+		if parsed, err := time.ParseDuration(windowStr); err == nil {
+			suppressionWindow = parsed
+		} else {
+			log.Printf("Warning: Invalid DEDUPE_SUPPRESSION_WINDOW value '%s', suppression disabled: %s", windowStr, err)
+		}
+	}
+
+	suppressionMaxReExecutions := 0
+	if maxStr := os.Getenv("DEDUPE_MAX_REEXECUTIONS"); maxStr != "" {
+		if parsed, err := strconv.Atoi(maxStr); err == nil {
+			suppressionMaxReExecutions = parsed
+		} else {
+			log.Printf("Warning: Invalid DEDUPE_MAX_REEXECUTIONS value '%s', ignoring: %s", maxStr, err)
+		}
+	}
+
+	suppressionReuseSuccessfulRunID := os.Getenv("DEDUPE_REUSE_SUCCESSFUL_RUN_ID") == "true"
+
+	dedupeTracker, err := dedupe.NewPostgresTracker(dedupeDB, dedupe.Config{
+		TTL:           dedupeTTL,
+		SweepInterval: dedupeSweepInterval,
+		Suppression: dedupe.SuppressionPolicy{
+			Window:               suppressionWindow,
+			MaxReExecutions:      suppressionMaxReExecutions,
+			ReuseSuccessfulRunID: suppressionReuseSuccessfulRunID,
+		},
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize dedupe tracker: %v", err)
 	}
-	log.Printf("✓ Dedupe tracking enabled")
+	log.Printf("✓ Dedupe tracking enabled (TTL=%s, sweep=%s, suppression_window=%s)", dedupeTTL, dedupeSweepInterval, suppressionWindow)
+
+	dedupeSweepCtx, cancelDedupeSweep := context.WithCancel(context.Background())
+	defer cancelDedupeSweep()
+	dedupeTracker.StartSweeper(dedupeSweepCtx)
+
+	workflowRunner.SetDedupeTracker(dedupeTracker)
+
+	// Initialize per-run log persistence, so a run's logs can be downloaded
+	// as a tar.gz bundle after the fact via GET /v1/runs/{run_id}/logs.tar.gz.
+	runLogDir := os.Getenv("RUN_LOG_DIR")
+	if runLogDir == "" {
+		runLogDir = "./run-logs"
+	}
+
+	runLogTTL := 7 * 24 * time.Hour
+	if ttlStr := os.Getenv("RUN_LOG_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil {
+			runLogTTL = parsed
+		} else {
+			log.Printf("Warning: Invalid RUN_LOG_TTL value '%s', using default: %s", ttlStr, runLogTTL)
+		}
+	}
+
+	runLogSweepInterval := time.Hour
+	if intervalStr := os.Getenv("RUN_LOG_SWEEP_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil {
+			runLogSweepInterval = parsed
+		} else {
+			log.Printf("Warning: Invalid RUN_LOG_SWEEP_INTERVAL value '%s', using default: %s", intervalStr, runLogSweepInterval)
+		}
+	}
+
+	runLogger, err := runlogs.NewRunLogger(runLogDir, runlogs.Config{
+		TTL:           runLogTTL,
+		SweepInterval: runLogSweepInterval,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize run logger: %v", err)
+	}
+	workflowRunner.SetRunLogger(runLogger)
+	log.Printf("✓ Run log persistence enabled (dir=%s, TTL=%s, sweep=%s)", runLogDir, runLogTTL, runLogSweepInterval)
+
+	runLogSweepCtx, cancelRunLogSweep := context.WithCancel(context.Background())
+	defer cancelRunLogSweep()
+	runLogger.StartSweeper(runLogSweepCtx)
+
+	logArchiver := runlogs.NewRunLogArchiver(runLogDir)
+
+	// Persist step-based progress snapshots (see WorkflowContext.ProgressReporter)
+	// in the same DBOS Postgres pool, so GetStatus can surface them across a
+	// worker restart instead of only while the reporting process is alive.
+	progressDB, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database for progress store: %v", err)
+	}
+	defer progressDB.Close()
+
+	progressStore, err := progressstore.NewPostgresStore(progressDB)
+	if err != nil {
+		log.Fatalf("Failed to initialize progress store: %v", err)
+	}
+	workflowRunner.SetProgressStore(progressStore)
+
+	// Resumable (tus 1.0) uploads: only available in embedded mode, since
+	// finishing an upload needs a streaming UploadContent call the
+	// simple-content HTTP client doesn't expose.
+	var resumableHandler *resumable.Handler
+	if contentService != nil {
+		resumableDB, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			log.Fatalf("Failed to open database for resumable upload store: %v", err)
+		}
+		defer resumableDB.Close()
+
+		resumableStore, err := resumable.NewPostgresStore(resumableDB)
+		if err != nil {
+			log.Fatalf("Failed to initialize resumable upload store: %v", err)
+		}
+
+		resumableDir := os.Getenv("RESUMABLE_UPLOAD_DIR")
+		if resumableDir == "" {
+			resumableDir = "./resumable-uploads"
+		}
+
+		var resumableMaxSize int64
+		if maxSizeStr := os.Getenv("RESUMABLE_MAX_SIZE"); maxSizeStr != "" {
+			if parsed, err := strconv.ParseInt(maxSizeStr, 10, 64); err == nil && parsed > 0 {
+				resumableMaxSize = parsed
+			} else {
+				log.Printf("Warning: Invalid RESUMABLE_MAX_SIZE value '%s', leaving unbounded", maxSizeStr)
+			}
+		}
+
+		resumableTTL := 24 * time.Hour
+		if ttlStr := os.Getenv("RESUMABLE_TTL"); ttlStr != "" {
+			if parsed, err := time.ParseDuration(ttlStr); err == nil {
+				resumableTTL = parsed
+			} else {
+				log.Printf("Warning: Invalid RESUMABLE_TTL value '%s', using default: %s", ttlStr, resumableTTL)
+			}
+		}
+
+		resumableSweepInterval := 15 * time.Minute
+		if intervalStr := os.Getenv("RESUMABLE_SWEEP_INTERVAL"); intervalStr != "" {
+			if parsed, err := time.ParseDuration(intervalStr); err == nil {
+				resumableSweepInterval = parsed
+			} else {
+				log.Printf("Warning: Invalid RESUMABLE_SWEEP_INTERVAL value '%s', using default: %s", intervalStr, resumableSweepInterval)
+			}
+		}
+
+		resumableCfg := resumable.Config{
+			MaxUploadSize: resumableMaxSize,
+			TTL:           resumableTTL,
+			SweepInterval: resumableSweepInterval,
+		}
+
+		resumableHandler, err = resumable.NewHandler(resumableStore, resumable.NewUploader(contentService), resumableDir, "/api/uploads", resumableCfg)
+		if err != nil {
+			log.Fatalf("Failed to initialize resumable upload handler: %v", err)
+		}
+
+		resumableSweepCtx, cancelResumableSweep := context.WithCancel(context.Background())
+		defer cancelResumableSweep()
+		resumableStore.StartSweeper(resumableSweepCtx, resumableCfg, nil)
+
+		log.Printf("✓ Resumable upload endpoint enabled (dir=%s, TTL=%s, sweep=%s)", resumableDir, resumableTTL, resumableSweepInterval)
+	}
 
 	// Create HTTP server
 	mux := http.NewServeMux()
 
 	// Create async handler (DBOS-only)
-	asyncHandler := handlers.NewAsyncHandler(workflowRunner, dedupeTracker)
+	asyncHandler := handlers.NewAsyncHandler(workflowRunner, dedupeTracker, logArchiver)
+	asyncHandler.SetIdleTracker(inflightTracker)
+
+	batchCacheDB, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database for batch response cache: %v", err)
+	}
+	defer batchCacheDB.Close()
+
+	batchCache, err := dedupe.NewPostgresBatchCache(batchCacheDB)
+	if err != nil {
+		log.Fatalf("Failed to initialize batch response cache: %v", err)
+	}
+	asyncHandler.SetBatchCache(batchCache)
+	registryHandler := handlers.NewRegistryHandler(dbosRuntime.Registry())
+	manifestHandler := handlers.NewManifestHandler(manifestRegistry, workflowRunner)
+
+	deadLetterDB, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database for dead letter store: %v", err)
+	}
+	defer deadLetterDB.Close()
+
+	deadLetterStore, err := deadletter.NewPostgresStore(deadLetterDB)
+	if err != nil {
+		log.Fatalf("Failed to initialize dead letter store: %v", err)
+	}
+	workflowRunner.SetDeadLetterStore(deadLetterStore)
+	deadLetterHandler := handlers.NewDeadLetterHandler(workflowRunner, deadLetterStore)
 
 	// Register handlers
 	mux.HandleFunc("/health", handleHealth)
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/v1/process", asyncHandler.HandleProcessAsync)
-	mux.HandleFunc("/v1/runs/", asyncHandler.HandleStatus)
+	mux.HandleFunc("/v1/process/batch", asyncHandler.HandleProcessBatch)
+	mux.HandleFunc("/v1/runs", asyncHandler.HandleList)
+	mux.HandleFunc("/v1/runs/", asyncHandler.HandleRuns)
+	mux.HandleFunc("/v1/workflows", registryHandler.HandleList)
+	mux.HandleFunc("/v1/workflows/manifests", manifestHandler.HandleManifests)
+	mux.HandleFunc("/v1/deadletters", deadLetterHandler.HandleList)
+	mux.HandleFunc("/v1/deadletters/", deadLetterHandler.HandleDeadLetters)
+
+	if resumableHandler != nil {
+		mux.HandleFunc("/api/uploads", resumableHandler.HandleCreate)
+		mux.HandleFunc("/api/uploads/", resumableHandler.HandleItem)
+	}
 
 	log.Printf("✓ Registered async endpoints")
 	log.Printf("✓ Metrics endpoint: http://%s/metrics", httpAddr)
@@ -220,7 +493,7 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	// Graceful shutdown with timeout
+	// Stop accepting new HTTP work first.
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -228,6 +501,29 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop the intent poller from dequeuing further work.
+	if poller != nil {
+		poller.Stop()
+	}
+
+	// Wait for in-flight handlers, poller executors, and workflow runs to
+	// drain before tearing down the DBOS runtime out from under them.
+	drainTimeout := 30 * time.Second
+	if v := os.Getenv("DRAIN_TIMEOUT"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			drainTimeout = parsed
+		} else {
+			log.Printf("Warning: Invalid DRAIN_TIMEOUT value '%s', using default: %s", v, drainTimeout)
+		}
+	}
+
+	log.Printf("Draining %d in-flight job(s) (timeout=%s)...", inflightTracker.Count(), drainTimeout)
+	if inflightTracker.WaitIdle(drainTimeout) {
+		log.Println("All in-flight jobs drained")
+	} else {
+		log.Printf("Warning: drain timeout exceeded with %d job(s) still in flight", inflightTracker.Count())
+	}
+
 	log.Println("Server stopped")
 }
 
@@ -142,6 +142,12 @@ func (p *PipelineRunner) GetWorkflowStatus(runID string) (*workflows.WorkflowSta
 	return p.runner.GetStatus(context.Background(), runID)
 }
 
+// CancelWorkflow requests cancellation of a running workflow, e.g. when a
+// user deletes the content it was processing upstream.
+func (p *PipelineRunner) CancelWorkflow(runID string) error {
+	return p.runner.Cancel(context.Background(), runID)
+}
+
 // shutdownPipeline gracefully stops the pipeline
 func shutdownPipeline(p *PipelineRunner) {
 	log.Println()
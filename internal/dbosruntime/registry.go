@@ -0,0 +1,128 @@
+package dbosruntime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrWorkflowNotRegistered is returned when StartWorkflow is called with a
+// name that no caller has registered via WorkflowRegistry.Register.
+// StartWorkflowByName does not return it: for an unregistered name it falls
+// back to the generic WorkflowInput{ContentID, Metadata} envelope.
+var ErrWorkflowNotRegistered = errors.New("workflow not registered")
+
+// InputBuilder builds a typed workflow input from the content ID and free-form
+// metadata passed to StartWorkflowByName. It lets each registered workflow
+// decide its own input shape instead of being forced into the generic
+// WorkflowInput{ContentID, Metadata} envelope.
+type InputBuilder func(contentID string, metadata map[string]interface{}) (interface{}, error)
+
+// WorkflowDefinition describes a workflow that can be started by name from
+// any language. The InputSchema is a JSON Schema document (as a
+// map[string]interface{}) that every input is validated against before the
+// workflow is enqueued, so Python and Go callers share one explicit
+// contract instead of an implicit field-naming convention.
+type WorkflowDefinition struct {
+	Name         string
+	Version      string
+	InputSchema  map[string]interface{}
+	InputBuilder InputBuilder
+}
+
+// WorkflowRegistry is a language-agnostic catalog of workflows that can be
+// started by name. Workers in any language register the workflows they can
+// execute; callers (Go, Python, or an HTTP client) look them up by name to
+// discover the expected input shape and version before starting a run.
+type WorkflowRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]WorkflowDefinition
+}
+
+// NewWorkflowRegistry creates an empty workflow registry.
+func NewWorkflowRegistry() *WorkflowRegistry {
+	return &WorkflowRegistry{
+		entries: make(map[string]WorkflowDefinition),
+	}
+}
+
+// Register adds a workflow definition to the registry. schema is a JSON
+// Schema document describing the shape StartWorkflow's input (or
+// inputBuilder's output, for StartWorkflowByName) must conform to.
+// Registering the same name twice replaces the previous definition, which
+// lets a newer binary version re-register with an updated schema.
+func (reg *WorkflowRegistry) Register(name, version string, schema map[string]interface{}, inputBuilder InputBuilder) error {
+	if name == "" {
+		return errors.New("workflow name is required")
+	}
+	if version == "" {
+		return errors.New("workflow version is required")
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.entries[name] = WorkflowDefinition{
+		Name:         name,
+		Version:      version,
+		InputSchema:  schema,
+		InputBuilder: inputBuilder,
+	}
+	return nil
+}
+
+// Lookup returns the definition registered under name, if any.
+func (reg *WorkflowRegistry) Lookup(name string) (WorkflowDefinition, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	def, ok := reg.entries[name]
+	return def, ok
+}
+
+// List returns every registered workflow definition, sorted by name, so
+// Python workers can discover callable workflows and their expected
+// inputs at startup.
+func (reg *WorkflowRegistry) List() []WorkflowDefinition {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	defs := make([]WorkflowDefinition, 0, len(reg.entries))
+	for _, def := range reg.entries {
+		defs = append(defs, def)
+	}
+	sortDefinitionsByName(defs)
+	return defs
+}
+
+func sortDefinitionsByName(defs []WorkflowDefinition) {
+	for i := 1; i < len(defs); i++ {
+		for j := i; j > 0 && defs[j-1].Name > defs[j].Name; j-- {
+			defs[j-1], defs[j] = defs[j], defs[j-1]
+		}
+	}
+}
+
+// validate checks versionConstraint (exact-match; "" accepts any version)
+// and marshals+validates input against the registered schema.
+func (def WorkflowDefinition) validate(versionConstraint string, input interface{}) ([]byte, error) {
+	if versionConstraint != "" && versionConstraint != def.Version {
+		return nil, fmt.Errorf("workflow %q is registered at version %q, which does not satisfy requested version %q", def.Name, def.Version, versionConstraint)
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input for workflow %q: %w", def.Name, err)
+	}
+
+	if def.InputSchema != nil {
+		var decoded interface{}
+		if err := json.Unmarshal(inputJSON, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to decode input for workflow %q: %w", def.Name, err)
+		}
+		if err := validateAgainstSchema(def.InputSchema, decoded); err != nil {
+			return nil, fmt.Errorf("input for workflow %q does not match registered schema: %w", def.Name, err)
+		}
+	}
+
+	return inputJSON, nil
+}
@@ -16,6 +16,7 @@ type Runtime struct {
 	queue       *dbos.WorkflowQueue
 	config      Config
 	db          *sql.DB
+	registry    *WorkflowRegistry
 }
 
 // NewRuntime creates a new DBOS runtime instance
@@ -53,6 +54,7 @@ func NewRuntime(ctx context.Context, cfg Config) (*Runtime, error) {
 		queue:       &queue,
 		config:      cfg,
 		db:          db,
+		registry:    NewWorkflowRegistry(),
 	}, nil
 }
 
@@ -84,3 +86,10 @@ func (r *Runtime) QueueName() string {
 func (r *Runtime) Concurrency() int {
 	return r.config.Concurrency
 }
+
+// Registry returns the runtime's workflow registry, which language-agnostic
+// workflow callers use to register input schemas and discover callable
+// workflows by name.
+func (r *Runtime) Registry() *WorkflowRegistry {
+	return r.registry
+}
@@ -0,0 +1,153 @@
+package dbosruntime
+
+import (
+	"fmt"
+	"sort"
+)
+
+// validateAgainstSchema checks data (already round-tripped through
+// encoding/json, so numbers are float64 and objects are
+// map[string]interface{}) against a minimal JSON Schema document.
+//
+// Only the subset of JSON Schema actually needed to describe workflow
+// inputs is supported: "type", "required", "properties", "items", and
+// "enum". Anything else in the schema is ignored rather than rejected, so
+// schemas can carry extra documentation keywords ("description", "title")
+// without tripping validation.
+func validateAgainstSchema(schema map[string]interface{}, data interface{}) error {
+	return validateNode(schema, data, "")
+}
+
+func validateNode(schema map[string]interface{}, data interface{}, path string) error {
+	if schema == nil {
+		return nil
+	}
+
+	if rawType, ok := schema["type"]; ok {
+		typeName, _ := rawType.(string)
+		if typeName != "" {
+			if err := validateType(typeName, data, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rawEnum, ok := schema["enum"]; ok {
+		if enum, ok := rawEnum.([]interface{}); ok && len(enum) > 0 {
+			if !enumContains(enum, data) {
+				return fmt.Errorf("%s: value %v is not one of the allowed enum values", fieldLabel(path), data)
+			}
+		}
+	}
+
+	obj, isObject := data.(map[string]interface{})
+
+	if rawRequired, ok := schema["required"]; ok && isObject {
+		required, _ := rawRequired.([]interface{})
+		missing := make([]string, 0, len(required))
+		for _, r := range required {
+			name, _ := r.(string)
+			if name == "" {
+				continue
+			}
+			if _, present := obj[name]; !present {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("%s: missing required field(s): %v", fieldLabel(path), missing)
+		}
+	}
+
+	if rawProps, ok := schema["properties"]; ok && isObject {
+		props, _ := rawProps.(map[string]interface{})
+		for name, rawPropSchema := range props {
+			propSchema, ok := rawPropSchema.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, present := obj[name]
+			if !present {
+				continue
+			}
+			if err := validateNode(propSchema, value, joinPath(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rawItems, ok := schema["items"]; ok {
+		itemSchema, ok := rawItems.(map[string]interface{})
+		if ok {
+			if items, ok := data.([]interface{}); ok {
+				for i, item := range items {
+					if err := validateNode(itemSchema, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateType(typeName string, data interface{}, path string) error {
+	if data == nil {
+		if typeName == "null" {
+			return nil
+		}
+		return fmt.Errorf("%s: expected type %q, got null", fieldLabel(path), typeName)
+	}
+
+	var ok bool
+	switch typeName {
+	case "object":
+		_, ok = data.(map[string]interface{})
+	case "array":
+		_, ok = data.([]interface{})
+	case "string":
+		_, ok = data.(string)
+	case "boolean":
+		_, ok = data.(bool)
+	case "number":
+		_, ok = data.(float64)
+	case "integer":
+		n, isNum := data.(float64)
+		ok = isNum && n == float64(int64(n))
+	case "null":
+		ok = data == nil
+	default:
+		// Unknown schema type keyword: nothing to check against.
+		return nil
+	}
+
+	if !ok {
+		return fmt.Errorf("%s: expected type %q, got %T", fieldLabel(path), typeName, data)
+	}
+	return nil
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldLabel(path string) string {
+	if path == "" {
+		return "input"
+	}
+	return "input." + path
+}
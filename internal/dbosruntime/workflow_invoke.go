@@ -5,33 +5,123 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/tendant/simple-content-pipeline/internal/dbosruntime")
+
 // WorkflowInput represents input to a DBOS workflow
 type WorkflowInput struct {
 	ContentID string                 `json:"content_id"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// StartWorkflowByName starts a DBOS workflow by name (language-agnostic)
-// This allows triggering workflows implemented in any language (Go, Python, etc.)
+// StartWorkflowByName starts a DBOS workflow by name (language-agnostic).
+// This allows triggering workflows implemented in any language (Go, Python, etc.).
+//
+// If workflowName has been registered with r.Registry(), the registered
+// InputBuilder turns (contentID, metadata) into the workflow's typed input,
+// which is validated against the registered JSON Schema before the
+// workflow is enqueued, and the row's application_version is tagged with
+// the registered workflow version. If workflowName is not registered, it
+// falls back to the legacy WorkflowInput{ContentID, Metadata} envelope for
+// backward compatibility with callers that haven't migrated to the
+// registry yet.
 func (r *Runtime) StartWorkflowByName(ctx context.Context, workflowName string, contentID string, metadata map[string]interface{}) (string, error) {
-	// Generate workflow UUID
-	workflowUUID := fmt.Sprintf("%s-%s-%d", workflowName, contentID, time.Now().UnixNano())
+	ctx, span := tracer.Start(ctx, "dbosruntime.StartWorkflowByName", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+		attribute.String("workflow.name", workflowName),
+	))
+	defer span.End()
+
+	if metadata == nil {
+		metadata = make(map[string]interface{}, 1)
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if traceparent := carrier.Get("traceparent"); traceparent != "" {
+		metadata["trace_id"] = traceparent
+	}
+
+	def, registered := r.registry.Lookup(workflowName)
+	if !registered {
+		inputJSON, err := json.Marshal(WorkflowInput{ContentID: contentID, Metadata: metadata})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return "", fmt.Errorf("failed to marshal input: %w", err)
+		}
+		return r.enqueueByName(ctx, span, workflowName, r.config.ApplicationVersion, inputJSON)
+	}
 
-	// Create input
-	input := WorkflowInput{
-		ContentID: contentID,
-		Metadata:  metadata,
+	if def.InputBuilder == nil {
+		err := fmt.Errorf("workflow %q is registered without an input builder; use StartWorkflow instead", workflowName)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
 	}
 
-	// Serialize input to JSON
-	inputJSON, err := json.Marshal(input)
+	input, err := def.InputBuilder(contentID, metadata)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal input: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", fmt.Errorf("failed to build input for workflow %q: %w", workflowName, err)
 	}
 
-	// Get database connection
+	inputJSON, err := def.validate("", input)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+
+	return r.enqueueByName(ctx, span, workflowName, def.Version, inputJSON)
+}
+
+// StartWorkflow starts a registered workflow by name with a typed input,
+// bypassing the (contentID, metadata) convenience shape StartWorkflowByName
+// uses. versionConstraint, if non-empty, must exactly match the version the
+// workflow was registered with; this lets a caller pin to a version it
+// knows how to talk to and get a clear error instead of a silently
+// mismatched payload when the registered workflow has moved on.
+func (r *Runtime) StartWorkflow(ctx context.Context, workflowName string, versionConstraint string, input interface{}) (string, error) {
+	ctx, span := tracer.Start(ctx, "dbosruntime.StartWorkflow", trace.WithAttributes(
+		attribute.String("workflow.name", workflowName),
+	))
+	defer span.End()
+
+	def, ok := r.registry.Lookup(workflowName)
+	if !ok {
+		err := fmt.Errorf("%w: %q", ErrWorkflowNotRegistered, workflowName)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+
+	inputJSON, err := def.validate(versionConstraint, input)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+
+	return r.enqueueByName(ctx, span, workflowName, def.Version, inputJSON)
+}
+
+// enqueueByName inserts a workflow row into dbos.workflow_status and
+// dbos.workflow_queue, the two tables DBOS polls regardless of which
+// language's worker picks the workflow up. It is shared by
+// StartWorkflowByName and StartWorkflow so both follow the same
+// insert-then-enqueue sequence and error wrapping.
+func (r *Runtime) enqueueByName(ctx context.Context, span trace.Span, workflowName, applicationVersion string, inputJSON []byte) (string, error) {
+	workflowUUID := fmt.Sprintf("%s-%d", workflowName, time.Now().UnixNano())
+	span.SetAttributes(attribute.String("workflow.uuid", workflowUUID))
+
 	db := r.db
 
 	// Insert workflow into dbos.workflow_status table
@@ -51,19 +141,21 @@ func (r *Runtime) StartWorkflowByName(ctx context.Context, workflowName string,
 	`
 
 	now := time.Now().UnixMilli()
-	_, err = db.ExecContext(ctx, query,
-		workflowUUID,           // workflow_uuid
-		"PENDING",              // status
-		workflowName,           // name (Python function name)
-		string(inputJSON),      // request
-		"pending",              // executor_id
-		now,                    // created_at
-		now,                    // updated_at
-		r.config.ApplicationVersion, // application_version
-		r.config.AppName,       // application_id
+	_, err := db.ExecContext(ctx, query,
+		workflowUUID,       // workflow_uuid
+		"PENDING",          // status
+		workflowName,       // name (Python function name)
+		string(inputJSON),  // request
+		"pending",          // executor_id
+		now,                // created_at
+		now,                // updated_at
+		applicationVersion, // application_version
+		r.config.AppName,   // application_id
 	)
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return "", fmt.Errorf("failed to insert workflow: %w", err)
 	}
 
@@ -83,12 +175,21 @@ func (r *Runtime) StartWorkflowByName(ctx context.Context, workflowName string,
 	)
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return "", fmt.Errorf("failed to enqueue workflow: %w", err)
 	}
 
 	return workflowUUID, nil
 }
 
+// ListRegisteredWorkflows returns every workflow registered with r.Registry(),
+// sorted by name, so callers (and the HTTP discovery endpoint) can show
+// Python or Go workers which workflows are callable and what input they expect.
+func (r *Runtime) ListRegisteredWorkflows() []WorkflowDefinition {
+	return r.registry.List()
+}
+
 // WorkflowStatusInfo represents the status of a workflow
 type WorkflowStatusInfo struct {
 	WorkflowUUID string
@@ -0,0 +1,53 @@
+// Package deadletter persists workflow runs that reached a terminal failure
+// - whether that's exhausting a retry policy (see retry.Policy and
+// WorkflowRunner.RegisterWithPolicy) or failing permanently on the first
+// attempt - so an operator can inspect them via GET /v1/deadletters and
+// resubmit a failed content ID via POST /v1/deadletters/{runID}/requeue
+// instead of the failure going unnoticed. It's the same shape as
+// internal/progressstore and internal/dedupe: a small interface with an
+// in-process MemoryStore and a Postgres-backed Store sharing the DBOS system
+// database pool.
+package deadletter
+
+import (
+	"context"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// Entry is one workflow run that reached a terminal failure.
+type Entry struct {
+	RunID   string
+	Job     string
+	Request pipeline.ProcessRequest
+
+	// LastError is the error message of the final attempt.
+	LastError string
+
+	// Attempts is the total number of attempts (across all retried steps)
+	// the run made before giving up, per WorkflowResult.Attempts. Workflows
+	// that don't use retry.Do for their steps leave this 0, even if the
+	// underlying failure was permanent rather than retried-and-exhausted.
+	Attempts int
+
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+}
+
+// Store persists and retrieves dead-lettered runs. MemoryStore backs it
+// with an in-process map for standalone mode; PostgresStore backs it with
+// the DBOS Postgres pool so entries survive restarts.
+type Store interface {
+	// Put records entry, replacing any existing entry for the same RunID.
+	Put(ctx context.Context, entry Entry) error
+
+	// Get returns the entry for runID. ok is false if runID has no entry.
+	Get(ctx context.Context, runID string) (entry Entry, ok bool, err error)
+
+	// List returns every dead-lettered entry, newest first.
+	List(ctx context.Context) ([]Entry, error)
+
+	// Delete removes runID's entry, e.g. after it has been requeued.
+	Delete(ctx context.Context, runID string) error
+}
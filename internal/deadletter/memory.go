@@ -0,0 +1,53 @@
+package deadletter
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-process Store, used by the standalone binary where
+// there's no DBOS Postgres pool to share. Entries reset on restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore creates a new in-memory dead-letter store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.RunID] = entry
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, runID string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[runID]
+	return entry, ok, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].LastFailedAt.After(out[j].LastFailedAt)
+	})
+	return out, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, runID)
+	return nil
+}
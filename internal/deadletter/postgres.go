@@ -0,0 +1,127 @@
+package deadletter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// PostgresStore is a Store backed by a workflow_dead_letters table, so a
+// dead-lettered run survives a worker restart. It's the implementation used
+// by the DBOS-backed worker, sharing the DBOS system database pool.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new Postgres-backed dead-letter store, creating
+// the workflow_dead_letters table if it doesn't already exist.
+func NewPostgresStore(db *sql.DB) (*PostgresStore, error) {
+	store := &PostgresStore{db: db}
+	if err := store.ensureSchema(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure workflow_dead_letters schema: %w", err)
+	}
+	return store, nil
+}
+
+func (s *PostgresStore) ensureSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS workflow_dead_letters (
+			run_id TEXT PRIMARY KEY,
+			job TEXT NOT NULL,
+			request JSONB NOT NULL,
+			last_error TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			first_failed_at TIMESTAMPTZ NOT NULL,
+			last_failed_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow_dead_letters table: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Put(ctx context.Context, entry Entry) error {
+	request, err := json.Marshal(entry.Request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter request: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO workflow_dead_letters (run_id, job, request, last_error, attempts, first_failed_at, last_failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (run_id) DO UPDATE
+		SET job = $2, request = $3, last_error = $4, attempts = $5, last_failed_at = $6
+	`, entry.RunID, entry.Job, request, entry.LastError, entry.Attempts, entry.LastFailedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Get(ctx context.Context, runID string) (Entry, bool, error) {
+	entry, found, err := s.scanOne(s.db.QueryRowContext(ctx, `
+		SELECT run_id, job, request, last_error, attempts, first_failed_at, last_failed_at
+		FROM workflow_dead_letters WHERE run_id = $1
+	`, runID))
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to load dead letter: %w", err)
+	}
+	return entry, found, nil
+}
+
+func (s *PostgresStore) List(ctx context.Context) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT run_id, job, request, last_error, attempts, first_failed_at, last_failed_at
+		FROM workflow_dead_letters ORDER BY last_failed_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var request []byte
+		if err := rows.Scan(&entry.RunID, &entry.Job, &request, &entry.LastError, &entry.Attempts, &entry.FirstFailedAt, &entry.LastFailedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		if err := json.Unmarshal(request, &entry.Request); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dead letter request: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, runID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM workflow_dead_letters WHERE run_id = $1`, runID)
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+	return nil
+}
+
+// scanner is the subset of *sql.Row used by scanOne, so it can be reused for
+// a single QueryRowContext result.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (s *PostgresStore) scanOne(row scanner) (Entry, bool, error) {
+	var entry Entry
+	var request []byte
+	err := row.Scan(&entry.RunID, &entry.Job, &request, &entry.LastError, &entry.Attempts, &entry.FirstFailedAt, &entry.LastFailedAt)
+	if err == sql.ErrNoRows {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	if err := json.Unmarshal(request, &entry.Request); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to unmarshal dead letter request: %w", err)
+	}
+	return entry, true, nil
+}
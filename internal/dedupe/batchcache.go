@@ -0,0 +1,23 @@
+package dedupe
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// BatchCache persists the full response of an idempotency-keyed batch
+// submission (see handlers.AsyncHandler.HandleProcessBatch), so a retried
+// POST /v1/process/batch with the same idempotency_key returns the
+// original response instead of re-recording dedupe counts and re-enqueuing
+// every item. PostgresBatchCache backs it with the DBOS Postgres pool so
+// entries survive restarts; MemoryBatchCache backs it with an in-process
+// map for standalone mode.
+type BatchCache interface {
+	// Get returns the cached response for key, and whether it was found and
+	// still within its TTL.
+	Get(ctx context.Context, key string) (response json.RawMessage, ok bool, err error)
+
+	// Put stores response under key, valid for ttl.
+	Put(ctx context.Context, key string, response json.RawMessage, ttl time.Duration) error
+}
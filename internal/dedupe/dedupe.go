@@ -2,85 +2,106 @@ package dedupe
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
-	"log"
+	"time"
 )
 
-// Tracker tracks duplicate workflow submissions
-type Tracker struct {
-	db *sql.DB
+// Config configures TTL-based expiry and the background sweeper for a
+// Tracker implementation.
+type Config struct {
+	// TTL is how long a dedupe row is kept after its last_seen_at before the
+	// sweeper purges it. Zero disables TTL-based expiry; rows are then kept
+	// until explicitly removed with PurgeBefore.
+	TTL time.Duration
+
+	// SweepInterval is how often StartSweeper checks for expired rows. Zero
+	// disables the background sweeper even if TTL is set.
+	SweepInterval time.Duration
+
+	// Suppression governs RecordOrSuppress's behavior. A zero value (Window
+	// 0) disables suppression entirely; RecordOrSuppress then only counts
+	// the submission and never suppresses.
+	Suppression SuppressionPolicy
 }
 
-// NewTracker creates a new dedupe tracker
-func NewTracker(db *sql.DB) (*Tracker, error) {
-	tracker := &Tracker{db: db}
+// SuppressionPolicy configures RecordOrSuppress's re-execution suppression
+// window for a (content_id, job) pair.
+type SuppressionPolicy struct {
+	// Window is how long a content_id/job's last terminal run is considered
+	// fresh enough to suppress a re-submission. Zero disables suppression.
+	Window time.Duration
 
-	// Create table if not exists
-	if err := tracker.ensureTable(); err != nil {
-		return nil, fmt.Errorf("failed to ensure dedupe table: %w", err)
-	}
+	// MaxReExecutions caps how many times a content_id/job may be submitted
+	// through RecordOrSuppress before it starts suppressing it regardless of
+	// whether its last run succeeded. The count is cumulative since the
+	// pair's first submission: it is not reset when Window elapses and is
+	// not removed by PurgeBefore; the cap only applies while the pair's last
+	// recorded outcome is within Window. Zero disables this cap
+	// (ReuseSuccessfulRunID is then the only suppression trigger).
+	MaxReExecutions int
 
-	return tracker, nil
+	// ReuseSuccessfulRunID, if true, makes RecordOrSuppress return the prior
+	// run's ID and suppressed=true when that run reached terminal success
+	// within Window, instead of reporting a fresh submission.
+	ReuseSuccessfulRunID bool
 }
 
-// ensureTable creates the process_dedupe table if it doesn't exist
-func (t *Tracker) ensureTable() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS process_dedupe (
-			content_id TEXT PRIMARY KEY,
-			pipeline TEXT,
-			pipeline_version INTEGER,
-			first_seen_at TIMESTAMPTZ DEFAULT NOW(),
-			last_seen_at TIMESTAMPTZ DEFAULT NOW(),
-			seen_count INTEGER DEFAULT 1
-		)
-	`
-
-	_, err := t.db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("failed to create process_dedupe table: %w", err)
-	}
-
-	log.Printf("✓ process_dedupe table ready")
-	return nil
-}
+// BatchRecordItem is one item of a RecordBatch call.
+type BatchRecordItem struct {
+	ContentID       string
+	Pipeline        string
+	PipelineVersion int
 
-// Record records a workflow submission and returns the seen count
-func (t *Tracker) Record(ctx context.Context, contentID string, pipeline string, pipelineVersion int) (int, error) {
-	// Upsert: increment seen_count if exists, insert if not
-	query := `
-		INSERT INTO process_dedupe (content_id, pipeline, pipeline_version, first_seen_at, last_seen_at, seen_count)
-		VALUES ($1, $2, $3, NOW(), NOW(), 1)
-		ON CONFLICT (content_id) DO UPDATE
-		SET last_seen_at = NOW(),
-		    seen_count = process_dedupe.seen_count + 1,
-		    pipeline = EXCLUDED.pipeline,
-		    pipeline_version = EXCLUDED.pipeline_version
-		RETURNING seen_count
-	`
-
-	var seenCount int
-	err := t.db.QueryRowContext(ctx, query, contentID, pipeline, pipelineVersion).Scan(&seenCount)
-	if err != nil {
-		return 0, fmt.Errorf("failed to record dedupe: %w", err)
-	}
-
-	return seenCount, nil
+	// IdempotencyKey, if set, records this item the same way RecordWithKey
+	// would instead of Record.
+	IdempotencyKey string
 }
 
-// GetSeenCount retrieves the seen count for a content ID
-func (t *Tracker) GetSeenCount(ctx context.Context, contentID string) (int, error) {
-	query := `SELECT seen_count FROM process_dedupe WHERE content_id = $1`
+// Tracker records workflow submissions for dedupe accounting and reports
+// how many times a given key has been seen. PostgresTracker backs it with
+// the DBOS Postgres pool so counts survive restarts; MemoryTracker backs it
+// with an in-process map for standalone mode.
+type Tracker interface {
+	// Record records a submission keyed on (contentID, pipeline,
+	// pipelineVersion) and returns the updated seen count.
+	Record(ctx context.Context, contentID string, pipeline string, pipelineVersion int) (int, error)
+
+	// RecordWithKey records a submission keyed on a caller-supplied
+	// idempotency key instead of contentID, so a retried enqueue of the
+	// exact same request is recognized as the same submission.
+	RecordWithKey(ctx context.Context, idempotencyKey string, contentID string, pipeline string, pipelineVersion int) (int, error)
+
+	// RecordBatch records every item in one atomic operation (a single
+	// Postgres transaction for PostgresTracker, a single critical section
+	// for MemoryTracker), so concurrent batch submissions can't interleave
+	// and produce inconsistent seen_count values. Returns each item's seen
+	// count in the same order as items.
+	RecordBatch(ctx context.Context, items []BatchRecordItem) ([]int, error)
+
+	// RecordOrSuppress counts a submission of (content_id, job), in a count
+	// kept separately from Record's per-pipeline counts, then consults the
+	// Config.Suppression policy and the (content_id, job)'s last recorded
+	// terminal outcome (see RecordOutcome): if that policy decides this
+	// submission should be suppressed, it returns the prior run's ID and
+	// suppressed=true instead of leaving it to the caller to enqueue a new
+	// run. seenCount is always returned, suppressed or not.
+	RecordOrSuppress(ctx context.Context, contentID string, job string) (runID string, suppressed bool, seenCount int, err error)
+
+	// RecordOutcome records contentID/job's run as having reached a terminal
+	// state ("succeeded" or "failed"), for RecordOrSuppress to consult on a
+	// later submission of the same content_id/job within the suppression
+	// window.
+	RecordOutcome(ctx context.Context, contentID string, job string, runID string, state string) error
+
+	// GetSeenCount returns the most recently recorded seen count for
+	// contentID, or 0 if it has never been recorded.
+	GetSeenCount(ctx context.Context, contentID string) (int, error)
 
-	var seenCount int
-	err := t.db.QueryRowContext(ctx, query, contentID).Scan(&seenCount)
-	if err == sql.ErrNoRows {
-		return 0, nil
-	}
-	if err != nil {
-		return 0, fmt.Errorf("failed to get seen count: %w", err)
-	}
+	// PurgeBefore deletes rows last seen before cutoff and returns how many
+	// were removed.
+	PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error)
 
-	return seenCount, nil
+	// StartSweeper launches a background purge loop driven by Config.TTL
+	// and Config.SweepInterval, until ctx is cancelled. A no-op if either is
+	// zero.
+	StartSweeper(ctx context.Context)
 }
@@ -0,0 +1,275 @@
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type memoryRow struct {
+	contentID       string
+	pipeline        string
+	pipelineVersion int
+	idempotencyKey  string
+	firstSeenAt     time.Time
+	lastSeenAt      time.Time
+	seenCount       int
+}
+
+// memoryOutcome is the last terminal run recorded for a (content_id, job)
+// pair, consulted by RecordOrSuppress.
+type memoryOutcome struct {
+	runID      string
+	state      string
+	recordedAt time.Time
+}
+
+// MemoryTracker is an in-process Tracker, used by the standalone binary
+// where there's no DBOS Postgres pool to share. Counts reset on restart.
+type MemoryTracker struct {
+	cfg Config
+
+	mu   sync.Mutex
+	rows map[string]*memoryRow // keyed by content_id|pipeline|pipeline_version
+	byIK map[string]*memoryRow // keyed by idempotency_key
+
+	outcomes map[string]*memoryOutcome // keyed by content_id|job
+
+	// suppressionSeenCounts tracks RecordOrSuppress's own submission count,
+	// keyed by content_id|job like outcomes. It's deliberately a separate
+	// keyspace from rows: rows is keyed by (content_id, pipeline,
+	// pipeline_version), and pipeline_version 0 is a real, reachable
+	// version, not a safe sentinel - reusing rows here with job standing in
+	// for pipeline and version forced to 0 would collide with (and corrupt
+	// the seen_count of) a genuine version-0 Record call.
+	suppressionSeenCounts map[string]int
+}
+
+// NewMemoryTracker creates a new in-memory dedupe tracker. cfg.TTL and
+// cfg.SweepInterval control the background sweeper started by
+// StartSweeper; a zero Config disables sweeping and rows must be purged
+// manually with PurgeBefore.
+func NewMemoryTracker(cfg Config) *MemoryTracker {
+	return &MemoryTracker{
+		cfg:                   cfg,
+		rows:                  make(map[string]*memoryRow),
+		byIK:                  make(map[string]*memoryRow),
+		outcomes:              make(map[string]*memoryOutcome),
+		suppressionSeenCounts: make(map[string]int),
+	}
+}
+
+// outcomeKey builds the key memoryOutcome entries are stored under.
+func outcomeKey(contentID, job string) string {
+	return fmt.Sprintf("%s\x00%s", contentID, job)
+}
+
+func memoryKey(contentID, pipeline string, pipelineVersion int) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", contentID, pipeline, pipelineVersion)
+}
+
+// Record records a workflow submission keyed on (content_id, pipeline,
+// pipeline_version) and returns the seen count.
+func (t *MemoryTracker) Record(ctx context.Context, contentID string, pipeline string, pipelineVersion int) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.recordLocked(contentID, pipeline, pipelineVersion), nil
+}
+
+// RecordWithKey records a workflow submission keyed on a caller-supplied
+// idempotencyKey instead of content_id, so a retried enqueue of the exact
+// same request is recognized as the same submission.
+func (t *MemoryTracker) RecordWithKey(ctx context.Context, idempotencyKey string, contentID string, pipeline string, pipelineVersion int) (int, error) {
+	if idempotencyKey == "" {
+		return 0, fmt.Errorf("idempotency key is required")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.recordWithKeyLocked(idempotencyKey, contentID, pipeline, pipelineVersion), nil
+}
+
+// RecordBatch records every item under a single lock, so a concurrent
+// Record/RecordWithKey/RecordBatch call can't interleave with it and
+// produce an inconsistent seen_count for any item in the batch.
+func (t *MemoryTracker) RecordBatch(ctx context.Context, items []BatchRecordItem) ([]int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seenCounts := make([]int, len(items))
+	for i, item := range items {
+		if item.IdempotencyKey != "" {
+			seenCounts[i] = t.recordWithKeyLocked(item.IdempotencyKey, item.ContentID, item.Pipeline, item.PipelineVersion)
+		} else {
+			seenCounts[i] = t.recordLocked(item.ContentID, item.Pipeline, item.PipelineVersion)
+		}
+	}
+	return seenCounts, nil
+}
+
+// RecordOrSuppress records a submission of (contentID, job) and, if
+// Config.Suppression decides the prior run is still fresh enough, returns
+// that run's ID with suppressed=true instead of leaving the caller to
+// enqueue a new run.
+func (t *MemoryTracker) RecordOrSuppress(ctx context.Context, contentID string, job string) (string, bool, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := outcomeKey(contentID, job)
+	t.suppressionSeenCounts[key]++
+	seenCount := t.suppressionSeenCounts[key]
+
+	policy := t.cfg.Suppression
+	if policy.Window <= 0 {
+		return "", false, seenCount, nil
+	}
+
+	outcome, ok := t.outcomes[outcomeKey(contentID, job)]
+	if !ok || time.Since(outcome.recordedAt) > policy.Window {
+		return "", false, seenCount, nil
+	}
+
+	if policy.ReuseSuccessfulRunID && outcome.state == "succeeded" {
+		return outcome.runID, true, seenCount, nil
+	}
+	if policy.MaxReExecutions > 0 && seenCount > policy.MaxReExecutions {
+		return outcome.runID, true, seenCount, nil
+	}
+
+	return "", false, seenCount, nil
+}
+
+// RecordOutcome records contentID/job's run as having reached a terminal
+// state, for a later RecordOrSuppress call to consult.
+func (t *MemoryTracker) RecordOutcome(ctx context.Context, contentID string, job string, runID string, state string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.outcomes[outcomeKey(contentID, job)] = &memoryOutcome{
+		runID:      runID,
+		state:      state,
+		recordedAt: time.Now(),
+	}
+	return nil
+}
+
+func (t *MemoryTracker) recordLocked(contentID string, pipeline string, pipelineVersion int) int {
+	key := memoryKey(contentID, pipeline, pipelineVersion)
+	row, ok := t.rows[key]
+	now := time.Now()
+	if !ok {
+		row = &memoryRow{
+			contentID:       contentID,
+			pipeline:        pipeline,
+			pipelineVersion: pipelineVersion,
+			firstSeenAt:     now,
+			seenCount:       0,
+		}
+		t.rows[key] = row
+	}
+	row.lastSeenAt = now
+	row.seenCount++
+	return row.seenCount
+}
+
+func (t *MemoryTracker) recordWithKeyLocked(idempotencyKey string, contentID string, pipeline string, pipelineVersion int) int {
+	row, ok := t.byIK[idempotencyKey]
+	now := time.Now()
+	if !ok {
+		row = &memoryRow{
+			contentID:       contentID,
+			pipeline:        pipeline,
+			pipelineVersion: pipelineVersion,
+			idempotencyKey:  idempotencyKey,
+			firstSeenAt:     now,
+			seenCount:       0,
+		}
+		t.byIK[idempotencyKey] = row
+	}
+	row.lastSeenAt = now
+	row.seenCount++
+	return row.seenCount
+}
+
+// GetSeenCount retrieves the seen count for a content ID. If the content ID
+// was recorded under more than one pipeline/version, the count for the most
+// recently seen one is returned.
+func (t *MemoryTracker) GetSeenCount(ctx context.Context, contentID string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var best *memoryRow
+	for _, row := range t.rows {
+		if row.contentID != contentID {
+			continue
+		}
+		if best == nil || row.lastSeenAt.After(best.lastSeenAt) {
+			best = row
+		}
+	}
+	for _, row := range t.byIK {
+		if row.contentID != contentID {
+			continue
+		}
+		if best == nil || row.lastSeenAt.After(best.lastSeenAt) {
+			best = row
+		}
+	}
+	if best == nil {
+		return 0, nil
+	}
+	return best.seenCount, nil
+}
+
+// PurgeBefore deletes dedupe rows whose last_seen_at is older than cutoff
+// and returns how many rows were removed.
+func (t *MemoryTracker) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var purged int64
+	for key, row := range t.rows {
+		if row.lastSeenAt.Before(cutoff) {
+			delete(t.rows, key)
+			purged++
+		}
+	}
+	for key, row := range t.byIK {
+		if row.lastSeenAt.Before(cutoff) {
+			delete(t.byIK, key)
+			purged++
+		}
+	}
+	for key, outcome := range t.outcomes {
+		if outcome.recordedAt.Before(cutoff) {
+			delete(t.outcomes, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// StartSweeper launches a background goroutine that purges dedupe rows
+// older than cfg.TTL every cfg.SweepInterval, until ctx is cancelled. It is
+// a no-op if TTL or SweepInterval is zero; call PurgeBefore directly in
+// that case if manual purging is needed.
+func (t *MemoryTracker) StartSweeper(ctx context.Context) {
+	if t.cfg.TTL <= 0 || t.cfg.SweepInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.cfg.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.PurgeBefore(ctx, time.Now().Add(-t.cfg.TTL))
+			}
+		}
+	}()
+}
@@ -0,0 +1,45 @@
+package dedupe
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type batchCacheEntry struct {
+	response  json.RawMessage
+	expiresAt time.Time
+}
+
+// MemoryBatchCache is an in-process BatchCache, used by the standalone
+// binary where there's no DBOS Postgres pool to share. Entries reset on
+// restart.
+type MemoryBatchCache struct {
+	mu      sync.Mutex
+	entries map[string]batchCacheEntry
+}
+
+// NewMemoryBatchCache creates a new in-memory batch response cache.
+func NewMemoryBatchCache() *MemoryBatchCache {
+	return &MemoryBatchCache{entries: make(map[string]batchCacheEntry)}
+}
+
+func (c *MemoryBatchCache) Get(ctx context.Context, key string) (json.RawMessage, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.response, true, nil
+}
+
+func (c *MemoryBatchCache) Put(ctx context.Context, key string, response json.RawMessage, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = batchCacheEntry{response: response, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
@@ -0,0 +1,433 @@
+package dedupe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter = otel.Meter("github.com/tendant/simple-content-pipeline/internal/dedupe")
+
+// PostgresTracker is a Tracker backed by a process_dedupe table, so counts
+// survive restarts. It's the implementation used by the DBOS-backed worker,
+// typically sharing the DBOS system database pool.
+type PostgresTracker struct {
+	db  *sql.DB
+	cfg Config
+
+	seenCounter   metric.Int64Counter
+	hitsCounter   metric.Int64Counter
+	missesCounter metric.Int64Counter
+	purgedCounter metric.Int64Counter
+}
+
+// NewPostgresTracker creates a new Postgres-backed dedupe tracker. cfg.TTL
+// and cfg.SweepInterval control the background sweeper started by
+// StartSweeper; a zero Config disables sweeping and rows must be purged
+// manually with PurgeBefore.
+func NewPostgresTracker(db *sql.DB, cfg Config) (*PostgresTracker, error) {
+	seenCounter, err := meter.Int64Counter("pipeline.dedupe.seen_count",
+		metric.WithDescription("Number of workflow submissions recorded by the dedupe tracker"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seen_count counter: %w", err)
+	}
+
+	hitsCounter, err := meter.Int64Counter("pipeline.dedupe.hits",
+		metric.WithDescription("Number of workflow submissions recorded as duplicates of an existing dedupe row"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hits counter: %w", err)
+	}
+
+	missesCounter, err := meter.Int64Counter("pipeline.dedupe.misses",
+		metric.WithDescription("Number of workflow submissions that created a new dedupe row"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create misses counter: %w", err)
+	}
+
+	purgedCounter, err := meter.Int64Counter("pipeline.dedupe.purged",
+		metric.WithDescription("Number of dedupe rows removed by PurgeBefore or the background sweeper"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create purged counter: %w", err)
+	}
+
+	tracker := &PostgresTracker{
+		db:            db,
+		cfg:           cfg,
+		seenCounter:   seenCounter,
+		hitsCounter:   hitsCounter,
+		missesCounter: missesCounter,
+		purgedCounter: purgedCounter,
+	}
+
+	// Create (and migrate, if needed) the dedupe table
+	if err := tracker.ensureSchema(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure dedupe schema: %w", err)
+	}
+
+	return tracker, nil
+}
+
+// ensureSchema creates the process_dedupe table if it doesn't exist, and
+// migrates deployments still running the original content_id-only primary
+// key to the composite (content_id, pipeline, pipeline_version) key so that
+// re-processing the same content under a different pipeline version no
+// longer collides with (and silently overwrites) an earlier run's row.
+func (t *PostgresTracker) ensureSchema(ctx context.Context) error {
+	_, err := t.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS process_dedupe (
+			content_id TEXT,
+			pipeline TEXT,
+			pipeline_version INTEGER,
+			idempotency_key TEXT,
+			first_seen_at TIMESTAMPTZ DEFAULT NOW(),
+			last_seen_at TIMESTAMPTZ DEFAULT NOW(),
+			seen_count INTEGER DEFAULT 1,
+			PRIMARY KEY (content_id, pipeline, pipeline_version)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create process_dedupe table: %w", err)
+	}
+
+	if _, err := t.db.ExecContext(ctx, `ALTER TABLE process_dedupe ADD COLUMN IF NOT EXISTS idempotency_key TEXT`); err != nil {
+		return fmt.Errorf("failed to add idempotency_key column: %w", err)
+	}
+
+	var pkColumns sql.NullString
+	err = t.db.QueryRowContext(ctx, `
+		SELECT string_agg(a.attname, ',' ORDER BY a.attnum)
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = 'process_dedupe'::regclass AND i.indisprimary
+	`).Scan(&pkColumns)
+	if err != nil {
+		return fmt.Errorf("failed to inspect process_dedupe primary key: %w", err)
+	}
+
+	if pkColumns.String != "content_id,pipeline,pipeline_version" {
+		if _, err := t.db.ExecContext(ctx, `ALTER TABLE process_dedupe DROP CONSTRAINT IF EXISTS process_dedupe_pkey`); err != nil {
+			return fmt.Errorf("failed to drop legacy process_dedupe primary key: %w", err)
+		}
+		if _, err := t.db.ExecContext(ctx, `ALTER TABLE process_dedupe ADD PRIMARY KEY (content_id, pipeline, pipeline_version)`); err != nil {
+			return fmt.Errorf("failed to add composite process_dedupe primary key: %w", err)
+		}
+		log.Printf("✓ migrated process_dedupe primary key to (content_id, pipeline, pipeline_version)")
+	}
+
+	if _, err := t.db.ExecContext(ctx, `
+		CREATE UNIQUE INDEX IF NOT EXISTS process_dedupe_idempotency_key_idx
+		ON process_dedupe (idempotency_key) WHERE idempotency_key IS NOT NULL
+	`); err != nil {
+		return fmt.Errorf("failed to create idempotency_key index: %w", err)
+	}
+
+	if _, err := t.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS process_dedupe_outcomes (
+			content_id TEXT,
+			job TEXT,
+			run_id TEXT NOT NULL,
+			state TEXT NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (content_id, job)
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create process_dedupe_outcomes table: %w", err)
+	}
+
+	// process_dedupe_suppression_counts tracks RecordOrSuppress's own
+	// submission count, keyed like process_dedupe_outcomes by (content_id,
+	// job). It's deliberately its own table rather than reusing
+	// process_dedupe with job standing in for pipeline: process_dedupe is
+	// keyed by (content_id, pipeline, pipeline_version), and pipeline_version
+	// 0 is a real, reachable version - overloading that keyspace here would
+	// collide with (and corrupt the seen_count of) a genuine version-0
+	// Record call.
+	if _, err := t.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS process_dedupe_suppression_counts (
+			content_id TEXT,
+			job TEXT,
+			seen_count INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (content_id, job)
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create process_dedupe_suppression_counts table: %w", err)
+	}
+
+	log.Printf("✓ process_dedupe table ready")
+	return nil
+}
+
+// Record records a workflow submission keyed on (content_id, pipeline,
+// pipeline_version) and returns the seen count.
+func (t *PostgresTracker) Record(ctx context.Context, contentID string, pipeline string, pipelineVersion int) (int, error) {
+	query := `
+		INSERT INTO process_dedupe (content_id, pipeline, pipeline_version, first_seen_at, last_seen_at, seen_count)
+		VALUES ($1, $2, $3, NOW(), NOW(), 1)
+		ON CONFLICT (content_id, pipeline, pipeline_version) DO UPDATE
+		SET last_seen_at = NOW(),
+		    seen_count = process_dedupe.seen_count + 1
+		RETURNING seen_count
+	`
+
+	var seenCount int
+	err := t.db.QueryRowContext(ctx, query, contentID, pipeline, pipelineVersion).Scan(&seenCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record dedupe: %w", err)
+	}
+
+	t.recordMetrics(ctx, seenCount)
+	return seenCount, nil
+}
+
+// RecordWithKey records a workflow submission keyed on a caller-supplied
+// idempotencyKey (e.g. a hash of the request body) instead of content_id,
+// so retried enqueues of the exact same request are recognized as the same
+// submission. contentID, pipeline, and pipelineVersion are still stored for
+// observability, but the dedupe identity is the idempotency key.
+func (t *PostgresTracker) RecordWithKey(ctx context.Context, idempotencyKey string, contentID string, pipeline string, pipelineVersion int) (int, error) {
+	if idempotencyKey == "" {
+		return 0, fmt.Errorf("idempotency key is required")
+	}
+
+	query := `
+		INSERT INTO process_dedupe (content_id, pipeline, pipeline_version, idempotency_key, first_seen_at, last_seen_at, seen_count)
+		VALUES ($1, $2, $3, $4, NOW(), NOW(), 1)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO UPDATE
+		SET last_seen_at = NOW(),
+		    seen_count = process_dedupe.seen_count + 1
+		RETURNING seen_count
+	`
+
+	var seenCount int
+	err := t.db.QueryRowContext(ctx, query, contentID, pipeline, pipelineVersion, idempotencyKey).Scan(&seenCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record dedupe by idempotency key: %w", err)
+	}
+
+	t.recordMetrics(ctx, seenCount)
+	return seenCount, nil
+}
+
+// RecordBatch records every item in a single transaction, so concurrent
+// batch submissions can't interleave and leave any item's seen_count
+// inconsistent with what its own batch actually recorded.
+func (t *PostgresTracker) RecordBatch(ctx context.Context, items []BatchRecordItem) ([]int, error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin dedupe batch transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	seenCounts := make([]int, len(items))
+	for i, item := range items {
+		var seenCount int
+		var err error
+		if item.IdempotencyKey != "" {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO process_dedupe (content_id, pipeline, pipeline_version, idempotency_key, first_seen_at, last_seen_at, seen_count)
+				VALUES ($1, $2, $3, $4, NOW(), NOW(), 1)
+				ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO UPDATE
+				SET last_seen_at = NOW(),
+				    seen_count = process_dedupe.seen_count + 1
+				RETURNING seen_count
+			`, item.ContentID, item.Pipeline, item.PipelineVersion, item.IdempotencyKey).Scan(&seenCount)
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO process_dedupe (content_id, pipeline, pipeline_version, first_seen_at, last_seen_at, seen_count)
+				VALUES ($1, $2, $3, NOW(), NOW(), 1)
+				ON CONFLICT (content_id, pipeline, pipeline_version) DO UPDATE
+				SET last_seen_at = NOW(),
+				    seen_count = process_dedupe.seen_count + 1
+				RETURNING seen_count
+			`, item.ContentID, item.Pipeline, item.PipelineVersion).Scan(&seenCount)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to record dedupe batch item %d: %w", i, err)
+		}
+		seenCounts[i] = seenCount
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit dedupe batch transaction: %w", err)
+	}
+
+	for _, seenCount := range seenCounts {
+		t.recordMetrics(ctx, seenCount)
+	}
+	return seenCounts, nil
+}
+
+// RecordOrSuppress counts a submission of (contentID, job) in
+// process_dedupe_suppression_counts (not in Record's process_dedupe rows),
+// then consults Config.Suppression and the pair's last recorded terminal
+// outcome (see RecordOutcome): if that policy decides the prior run is
+// still fresh enough, it returns that run's ID with suppressed=true instead
+// of leaving the caller to enqueue a new run.
+func (t *PostgresTracker) RecordOrSuppress(ctx context.Context, contentID string, job string) (string, bool, int, error) {
+	seenCount, err := t.recordSuppressionSeenCount(ctx, contentID, job)
+	if err != nil {
+		return "", false, 0, err
+	}
+
+	policy := t.cfg.Suppression
+	if policy.Window <= 0 {
+		return "", false, seenCount, nil
+	}
+
+	var runID, state string
+	var recordedAt time.Time
+	err = t.db.QueryRowContext(ctx, `
+		SELECT run_id, state, recorded_at FROM process_dedupe_outcomes
+		WHERE content_id = $1 AND job = $2
+	`, contentID, job).Scan(&runID, &state, &recordedAt)
+	if err == sql.ErrNoRows {
+		return "", false, seenCount, nil
+	}
+	if err != nil {
+		return "", false, seenCount, fmt.Errorf("failed to load dedupe outcome: %w", err)
+	}
+
+	if time.Since(recordedAt) > policy.Window {
+		return "", false, seenCount, nil
+	}
+
+	if policy.ReuseSuccessfulRunID && state == "succeeded" {
+		return runID, true, seenCount, nil
+	}
+	if policy.MaxReExecutions > 0 && seenCount > policy.MaxReExecutions {
+		return runID, true, seenCount, nil
+	}
+
+	return "", false, seenCount, nil
+}
+
+// RecordOutcome records contentID/job's run as having reached a terminal
+// state, for a later RecordOrSuppress call to consult.
+func (t *PostgresTracker) RecordOutcome(ctx context.Context, contentID string, job string, runID string, state string) error {
+	_, err := t.db.ExecContext(ctx, `
+		INSERT INTO process_dedupe_outcomes (content_id, job, run_id, state, recorded_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (content_id, job) DO UPDATE
+		SET run_id = $3, state = $4, recorded_at = NOW()
+	`, contentID, job, runID, state)
+	if err != nil {
+		return fmt.Errorf("failed to record dedupe outcome: %w", err)
+	}
+	return nil
+}
+
+// recordSuppressionSeenCount increments and returns (content_id, job)'s
+// submission count in process_dedupe_suppression_counts, for
+// RecordOrSuppress's MaxReExecutions check. See that table's comment in
+// ensureSchema for why this doesn't reuse process_dedupe.
+func (t *PostgresTracker) recordSuppressionSeenCount(ctx context.Context, contentID, job string) (int, error) {
+	var seenCount int
+	err := t.db.QueryRowContext(ctx, `
+		INSERT INTO process_dedupe_suppression_counts (content_id, job, seen_count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (content_id, job) DO UPDATE
+		SET seen_count = process_dedupe_suppression_counts.seen_count + 1
+		RETURNING seen_count
+	`, contentID, job).Scan(&seenCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record suppression seen count: %w", err)
+	}
+	return seenCount, nil
+}
+
+func (t *PostgresTracker) recordMetrics(ctx context.Context, seenCount int) {
+	t.seenCounter.Add(ctx, 1)
+	if seenCount > 1 {
+		t.hitsCounter.Add(ctx, 1)
+	} else {
+		t.missesCounter.Add(ctx, 1)
+	}
+}
+
+// GetSeenCount retrieves the seen count for a content ID. If the content ID
+// was recorded under more than one pipeline/version, the count for the most
+// recently seen one is returned.
+func (t *PostgresTracker) GetSeenCount(ctx context.Context, contentID string) (int, error) {
+	query := `
+		SELECT seen_count FROM process_dedupe
+		WHERE content_id = $1
+		ORDER BY last_seen_at DESC
+		LIMIT 1
+	`
+
+	var seenCount int
+	err := t.db.QueryRowContext(ctx, query, contentID).Scan(&seenCount)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get seen count: %w", err)
+	}
+
+	return seenCount, nil
+}
+
+// PurgeBefore deletes dedupe rows (and expired suppression outcomes) whose
+// last_seen_at/recorded_at is older than cutoff and returns how many rows
+// were removed.
+func (t *PostgresTracker) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := t.db.ExecContext(ctx, `DELETE FROM process_dedupe WHERE last_seen_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dedupe rows: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purged dedupe rows: %w", err)
+	}
+
+	outcomeResult, err := t.db.ExecContext(ctx, `DELETE FROM process_dedupe_outcomes WHERE recorded_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dedupe outcome rows: %w", err)
+	}
+	outcomesPurged, err := outcomeResult.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purged dedupe outcome rows: %w", err)
+	}
+	purged += outcomesPurged
+
+	t.purgedCounter.Add(ctx, purged)
+	return purged, nil
+}
+
+// StartSweeper launches a background goroutine that purges dedupe rows
+// older than cfg.TTL every cfg.SweepInterval, until ctx is cancelled. It is
+// a no-op if TTL or SweepInterval is zero; call PurgeBefore directly in
+// that case if manual purging is needed.
+func (t *PostgresTracker) StartSweeper(ctx context.Context) {
+	if t.cfg.TTL <= 0 || t.cfg.SweepInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.cfg.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cutoff := time.Now().Add(-t.cfg.TTL)
+				purged, err := t.PurgeBefore(ctx, cutoff)
+				if err != nil {
+					log.Printf("dedupe sweep failed: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("dedupe sweep purged %d expired row(s) older than %s", purged, cutoff.Format(time.RFC3339))
+				}
+			}
+		}
+	}()
+}
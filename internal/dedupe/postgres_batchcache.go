@@ -0,0 +1,67 @@
+package dedupe
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PostgresBatchCache is a BatchCache backed by a batch_response_cache
+// table, so a retried batch submission is recognized as such even after a
+// worker restart.
+type PostgresBatchCache struct {
+	db *sql.DB
+}
+
+// NewPostgresBatchCache creates a new Postgres-backed batch response cache,
+// creating the batch_response_cache table if it doesn't already exist.
+func NewPostgresBatchCache(db *sql.DB) (*PostgresBatchCache, error) {
+	cache := &PostgresBatchCache{db: db}
+	if err := cache.ensureSchema(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure batch_response_cache schema: %w", err)
+	}
+	return cache, nil
+}
+
+func (c *PostgresBatchCache) ensureSchema(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS batch_response_cache (
+			idempotency_key TEXT PRIMARY KEY,
+			response JSONB NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create batch_response_cache table: %w", err)
+	}
+	return nil
+}
+
+func (c *PostgresBatchCache) Get(ctx context.Context, key string) (json.RawMessage, bool, error) {
+	var response json.RawMessage
+	err := c.db.QueryRowContext(ctx, `
+		SELECT response FROM batch_response_cache WHERE idempotency_key = $1 AND expires_at > NOW()
+	`, key).Scan(&response)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load cached batch response: %w", err)
+	}
+	return response, true, nil
+}
+
+func (c *PostgresBatchCache) Put(ctx context.Context, key string, response json.RawMessage, ttl time.Duration) error {
+	_, err := c.db.ExecContext(ctx, `
+		INSERT INTO batch_response_cache (idempotency_key, response, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET response = $2, expires_at = NOW() + $3 * INTERVAL '1 second'
+	`, key, response, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to save cached batch response: %w", err)
+	}
+	return nil
+}
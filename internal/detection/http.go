@@ -0,0 +1,63 @@
+// Package detection provides object-detection backends for the object
+// detection workflow.
+package detection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Detection is a single detected object: its label, confidence score, and
+// normalized bounding box as [x_min, y_min, x_max, y_max].
+type Detection struct {
+	Label string     `json:"label"`
+	Score float64    `json:"score"`
+	Box   [4]float64 `json:"box"`
+}
+
+// HTTPDetector calls an ONNX-backed object detection model server over
+// HTTP, POSTing image bytes and decoding a JSON detections response.
+// Modeled on an inference server's HTTP/REST front door (e.g. Triton, or a
+// thin gRPC-to-HTTP gateway in front of the ONNX runtime).
+type HTTPDetector struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPDetector creates a detector that POSTs to {baseURL}/v1/detect.
+func NewHTTPDetector(baseURL string) *HTTPDetector {
+	return &HTTPDetector{baseURL: baseURL, httpClient: &http.Client{}}
+}
+
+// Detect sends the image bytes in r to the detection service and returns
+// its detections.
+func (d *HTTPDetector) Detect(ctx context.Context, r io.Reader) ([]Detection, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.baseURL+"/v1/detect", r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create detect request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("detect request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("detect failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Detections []Detection `json:"detections"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode detect response: %w", err)
+	}
+
+	return result.Detections, nil
+}
@@ -7,15 +7,20 @@ import (
 	"log"
 
 	"github.com/google/uuid"
-	simpleworkflow "github.com/tendant/simple-workflow"
+	"github.com/tendant/simple-content-pipeline/internal/idle"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline/retry"
+	simpleworkflow "github.com/tendant/simple-workflow"
 )
 
 // ThumbnailExecutor implements simpleworkflow.WorkflowExecutor for thumbnail generation
 type ThumbnailExecutor struct {
 	contentReader workflows.ContentReader
 	derivedWriter workflows.DerivedWriter
+	idleTracker   *idle.Tracker
+	derivedGuard  *workflows.DerivedGuard
+	retryPolicy   retry.Policy
 }
 
 // NewThumbnailExecutor creates a new thumbnail executor
@@ -26,18 +31,36 @@ func NewThumbnailExecutor(
 	return &ThumbnailExecutor{
 		contentReader: contentReader,
 		derivedWriter: derivedWriter,
+		derivedGuard:  workflows.NewDerivedGuard(),
+		retryPolicy:   retry.DefaultPolicy(),
 	}
 }
 
+// SetRetryPolicy overrides the retry-with-backoff policy applied to this
+// executor's workflow steps. Defaults to retry.DefaultPolicy().
+func (e *ThumbnailExecutor) SetRetryPolicy(policy retry.Policy) {
+	e.retryPolicy = policy
+}
+
+// SetIdleTracker attaches an idle.Tracker so a shutdown path can wait for an
+// in-flight thumbnail execution to finish before the poller and DBOS
+// runtime are torn down. Passing nil (the default) disables tracking.
+func (e *ThumbnailExecutor) SetIdleTracker(tracker *idle.Tracker) {
+	e.idleTracker = tracker
+}
+
 // Execute implements simpleworkflow.WorkflowExecutor
-func (e *ThumbnailExecutor) Execute(ctx context.Context, run *simpleworkflow.WorkflowRun) (interface{}, error) {
+func (e *ThumbnailExecutor) Execute(ctx context.Context, intent *simpleworkflow.WorkflowIntent) (interface{}, error) {
+	done := e.idleTracker.Inc()
+	defer done()
+
 	// Parse payload
 	var params struct {
 		ContentID string `json:"content_id"`
 		Width     int    `json:"width"`
 		Height    int    `json:"height"`
 	}
-	if err := json.Unmarshal(run.Payload, &params); err != nil {
+	if err := json.Unmarshal(intent.Payload, &params); err != nil {
 		return nil, fmt.Errorf("failed to parse payload: %w", err)
 	}
 
@@ -62,9 +85,11 @@ func (e *ThumbnailExecutor) Execute(ctx context.Context, run *simpleworkflow.Wor
 
 	// Build workflow context
 	wctx := &workflows.WorkflowContext{
-		Ctx:     ctx,
-		Request: request,
-		RunID:   runID,
+		Ctx:          ctx,
+		Request:      request,
+		RunID:        runID,
+		DerivedGuard: e.derivedGuard,
+		RetryPolicy:  e.retryPolicy,
 	}
 
 	// Execute thumbnail workflow directly
@@ -5,28 +5,62 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/tendant/simple-content-pipeline/internal/dedupe"
+	"github.com/tendant/simple-content-pipeline/internal/idle"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/runlogs"
 )
 
+// batchIdempotencyTTL is how long HandleProcessBatch remembers a batch
+// idempotency_key's response, so a retried submission of the same batch
+// within this window is replayed instead of re-processed.
+const batchIdempotencyTTL = 24 * time.Hour
+
 // AsyncHandler handles asynchronous workflow requests
 type AsyncHandler struct {
 	workflowRunner *workflows.WorkflowRunner
-	dedupeTracker  *dedupe.Tracker
+	dedupeTracker  dedupe.Tracker
+	logArchiver    *runlogs.RunLogArchiver
+	idleTracker    *idle.Tracker
+	batchCache     dedupe.BatchCache
 }
 
-// NewAsyncHandler creates a new async handler
-func NewAsyncHandler(runner *workflows.WorkflowRunner, tracker *dedupe.Tracker) *AsyncHandler {
+// NewAsyncHandler creates a new async handler. logArchiver may be nil, in
+// which case GET .../logs.tar.gz responds 404 for every run.
+func NewAsyncHandler(runner *workflows.WorkflowRunner, tracker dedupe.Tracker, logArchiver *runlogs.RunLogArchiver) *AsyncHandler {
 	return &AsyncHandler{
 		workflowRunner: runner,
 		dedupeTracker:  tracker,
+		logArchiver:    logArchiver,
 	}
 }
 
+// SetIdleTracker attaches an idle.Tracker so a shutdown path can wait for
+// in-flight enqueue requests (HandleProcessAsync, HandleProcessBatch) to
+// finish before the HTTP listener and DBOS runtime are torn down. Passing
+// nil (the default) disables tracking.
+func (h *AsyncHandler) SetIdleTracker(tracker *idle.Tracker) {
+	h.idleTracker = tracker
+}
+
+// SetBatchCache attaches a dedupe.BatchCache so HandleProcessBatch can
+// replay the original response for a retried idempotency_key instead of
+// re-recording dedupe counts and re-enqueueing every item. Passing nil (the
+// default) disables batch-level idempotency; per-item dedupe still applies.
+func (h *AsyncHandler) SetBatchCache(cache dedupe.BatchCache) {
+	h.batchCache = cache
+}
+
 // HandleProcessAsync handles POST /v1/process - enqueues workflow and returns immediately
 func (h *AsyncHandler) HandleProcessAsync(w http.ResponseWriter, r *http.Request) {
+	done := h.idleTracker.Inc()
+	defer done()
+
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -51,10 +85,14 @@ func (h *AsyncHandler) HandleProcessAsync(w http.ResponseWriter, r *http.Request
 
 	log.Printf("Enqueueing workflow: content_id=%s, job=%s", req.ContentID, req.Job)
 
-	// Record dedupe submission (track how many times this content has been submitted)
+	// Record dedupe submission (track how many times this content has been
+	// submitted). If the caller supplied an idempotency key, dedupe on that
+	// instead of content_id so a retried enqueue is recognized as the same
+	// submission - RecordOrSuppress's suppression policy only applies to the
+	// plain content_id/job path below.
 	seenCount := 0
-	if h.dedupeTracker != nil {
-		count, err := h.dedupeTracker.Record(r.Context(), req.ContentID, req.Job, 1)
+	if h.dedupeTracker != nil && req.IdempotencyKey != "" {
+		count, err := h.dedupeTracker.RecordWithKey(r.Context(), req.IdempotencyKey, req.ContentID, req.Job, 1)
 		if err != nil {
 			log.Printf("Warning: Failed to record dedupe: %v (continuing anyway)", err)
 		} else {
@@ -63,6 +101,28 @@ func (h *AsyncHandler) HandleProcessAsync(w http.ResponseWriter, r *http.Request
 				log.Printf("Duplicate submission detected: content_id=%s, seen_count=%d", req.ContentID, seenCount)
 			}
 		}
+	} else if h.dedupeTracker != nil {
+		reusedRunID, suppressed, count, err := h.dedupeTracker.RecordOrSuppress(r.Context(), req.ContentID, req.Job)
+		if err != nil {
+			log.Printf("Warning: Failed to record dedupe: %v (continuing anyway)", err)
+		} else {
+			seenCount = count
+			if suppressed {
+				log.Printf("Submission suppressed, reusing prior run: content_id=%s, job=%s, run_id=%s", req.ContentID, req.Job, reusedRunID)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				json.NewEncoder(w).Encode(pipeline.ProcessResponse{
+					RunID:           reusedRunID,
+					DedupeSeenCount: seenCount,
+					Suppressed:      true,
+					ReusedRunID:     true,
+				})
+				return
+			}
+			if seenCount > 1 {
+				log.Printf("Duplicate submission detected: content_id=%s, seen_count=%d", req.ContentID, seenCount)
+			}
+		}
 	}
 
 	// Enqueue workflow (non-blocking)
@@ -86,15 +146,196 @@ func (h *AsyncHandler) HandleProcessAsync(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(resp)
 }
 
-// HandleStatus handles GET /v1/runs/{runID} - returns workflow status
-func (h *AsyncHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// HandleProcessBatch handles POST /v1/process/batch - enqueues many
+// workflows in one call, deduping per-item against both the dedupe tracker
+// (for the seen-count accounting) and DBOS's own workflow state (to skip
+// re-enqueuing work that already completed successfully).
+func (h *AsyncHandler) HandleProcessBatch(w http.ResponseWriter, r *http.Request) {
+	done := h.idleTracker.Inc()
+	defer done()
+
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Extract runID from URL path (/v1/runs/{runID})
-	runID := r.URL.Path[len("/v1/runs/"):]
+	var req pipeline.BatchProcessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Items) == 0 {
+		http.Error(w, "items must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	// A retried submission of the same batch (same idempotency_key, within
+	// TTL) replays the original response instead of re-recording dedupe
+	// counts and re-enqueueing every item.
+	if req.IdempotencyKey != "" && h.batchCache != nil {
+		if cached, ok, err := h.batchCache.Get(r.Context(), req.IdempotencyKey); err != nil {
+			log.Printf("Warning: Failed to load cached batch response for idempotency_key=%s: %v (continuing anyway)", req.IdempotencyKey, err)
+		} else if ok {
+			log.Printf("Replaying cached batch response: idempotency_key=%s", req.IdempotencyKey)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(batchStatusCode(cached))
+			w.Write(cached)
+			return
+		}
+	}
+
+	log.Printf("Enqueueing batch of %d item(s)", len(req.Items))
+
+	// Derive a per-item idempotency key for items that don't have their own,
+	// so items don't collide with each other under the same batch
+	// submission, then record the whole batch's dedupe accounting in one
+	// atomic operation so concurrent retries can't produce inconsistent
+	// seen counts.
+	recordItems := make([]dedupe.BatchRecordItem, len(req.Items))
+	for i, item := range req.Items {
+		idempotencyKey := item.IdempotencyKey
+		if idempotencyKey == "" && req.IdempotencyKey != "" {
+			idempotencyKey = fmt.Sprintf("%s-%d", req.IdempotencyKey, i)
+		}
+		recordItems[i] = dedupe.BatchRecordItem{
+			ContentID:       item.ContentID,
+			Pipeline:        item.Job,
+			PipelineVersion: 1,
+			IdempotencyKey:  idempotencyKey,
+		}
+	}
+
+	seenCounts := make([]int, len(req.Items))
+	if h.dedupeTracker != nil {
+		counts, err := h.dedupeTracker.RecordBatch(r.Context(), recordItems)
+		if err != nil {
+			log.Printf("Warning: Failed to record batch dedupe: %v (continuing anyway)", err)
+		} else {
+			seenCounts = counts
+		}
+	}
+
+	resp := pipeline.BatchProcessResponse{
+		Items: make([]pipeline.BatchItemResult, 0, len(req.Items)),
+	}
+	anyFailed := false
+
+	for i, item := range req.Items {
+		if item.ContentID == "" || item.Job == "" {
+			resp.Items = append(resp.Items, pipeline.BatchItemResult{
+				ContentID: item.ContentID,
+				Error:     "content_id and job are required",
+				Status:    "failed",
+			})
+			anyFailed = true
+			continue
+		}
+		item.IdempotencyKey = recordItems[i].IdempotencyKey
+
+		seenCount := seenCounts[i]
+		resp.DedupeSeenCount += seenCount
+
+		runID, deduped, err := h.workflowRunner.RunAsyncDeduped(r.Context(), item)
+		if err != nil {
+			log.Printf("Failed to enqueue batch item content_id=%s: %v", item.ContentID, err)
+			resp.Items = append(resp.Items, pipeline.BatchItemResult{
+				ContentID:       item.ContentID,
+				Error:           err.Error(),
+				DedupeSeenCount: seenCount,
+				Status:          "failed",
+			})
+			anyFailed = true
+			continue
+		}
+
+		resp.Items = append(resp.Items, pipeline.BatchItemResult{
+			ContentID:       item.ContentID,
+			RunID:           runID,
+			Deduped:         deduped,
+			DedupeSeenCount: seenCount,
+			Status:          "enqueued",
+		})
+	}
+
+	log.Printf("Batch enqueued: %d item(s), dedupe_seen_count=%d", len(resp.Items), resp.DedupeSeenCount)
+
+	statusCode := http.StatusAccepted
+	if anyFailed {
+		statusCode = http.StatusMultiStatus
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Failed to marshal batch response: %v", err)
+		http.Error(w, "Failed to marshal batch response", http.StatusInternalServerError)
+		return
+	}
+
+	if req.IdempotencyKey != "" && h.batchCache != nil {
+		if err := h.batchCache.Put(r.Context(), req.IdempotencyKey, body, batchIdempotencyTTL); err != nil {
+			log.Printf("Warning: Failed to cache batch response for idempotency_key=%s: %v (continuing anyway)", req.IdempotencyKey, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// batchStatusCode recomputes the HTTP status a cached batch response was
+// originally returned with, so a cache replay matches what a fresh
+// submission would respond with: 207 Multi-Status if any item failed, 202
+// Accepted otherwise.
+func batchStatusCode(cached json.RawMessage) int {
+	var resp pipeline.BatchProcessResponse
+	if err := json.Unmarshal(cached, &resp); err != nil {
+		return http.StatusAccepted
+	}
+	for _, item := range resp.Items {
+		if item.Status == "failed" {
+			return http.StatusMultiStatus
+		}
+	}
+	return http.StatusAccepted
+}
+
+// HandleRuns dispatches requests under /v1/runs/{run_id}[/cancel|/retry] to
+// the matching action, since they all share the path prefix. GET with no
+// suffix returns status; DELETE with no suffix cancels the run (the same
+// action as POST .../cancel, for callers that prefer the DELETE verb); POST
+// with a /cancel or /retry suffix triggers that action.
+func (h *AsyncHandler) HandleRuns(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/runs/")
+
+	switch {
+	case strings.HasSuffix(path, "/cancel"):
+		h.handleCancel(w, r, strings.TrimSuffix(path, "/cancel"))
+	case strings.HasSuffix(path, "/retry"):
+		h.handleRetry(w, r, strings.TrimSuffix(path, "/retry"))
+	case strings.HasSuffix(path, "/events"):
+		runID := strings.TrimSuffix(path, "/events")
+		if r.URL.Query().Get("type") == "status" {
+			h.handleStatusStream(w, r, runID)
+		} else {
+			h.handleEvents(w, r, runID)
+		}
+	case strings.HasSuffix(path, "/logs.tar.gz"):
+		h.handleLogArchive(w, r, strings.TrimSuffix(path, "/logs.tar.gz"))
+	case strings.HasSuffix(path, "/logs"):
+		h.handleLogs(w, r, strings.TrimSuffix(path, "/logs"))
+	case r.Method == http.MethodDelete:
+		h.handleCancel(w, r, path)
+	default:
+		h.handleStatus(w, r, path)
+	}
+}
+
+// handleStatus handles GET /v1/runs/{run_id} - returns workflow status
+func (h *AsyncHandler) handleStatus(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 	if runID == "" {
 		http.Error(w, "run_id is required", http.StatusBadRequest)
 		return
@@ -102,7 +343,6 @@ func (h *AsyncHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Checking workflow status: run_id=%s", runID)
 
-	// Get status
 	status, err := h.workflowRunner.GetStatus(r.Context(), runID)
 	if err != nil {
 		log.Printf("Failed to get workflow status: %v", err)
@@ -114,3 +354,98 @@ func (h *AsyncHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(status)
 }
+
+// handleCancel handles POST /v1/runs/{run_id}/cancel and DELETE
+// /v1/runs/{run_id} - requests cancellation of a running workflow.
+func (h *AsyncHandler) handleCancel(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Cancelling workflow: run_id=%s", runID)
+
+	if err := h.workflowRunner.Cancel(r.Context(), runID); err != nil {
+		log.Printf("Failed to cancel workflow: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to cancel workflow: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"run_id": runID, "status": "cancelling"})
+}
+
+// handleRetry handles POST /v1/runs/{run_id}/retry - resumes a cancelled or
+// failed workflow from its last checkpoint.
+func (h *AsyncHandler) handleRetry(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Retrying workflow: run_id=%s", runID)
+
+	newRunID, err := h.workflowRunner.Retry(r.Context(), runID)
+	if err != nil {
+		log.Printf("Failed to retry workflow: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to retry workflow: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(pipeline.ProcessResponse{RunID: newRunID})
+}
+
+// HandleList handles GET /v1/runs?job=&state=&since=&limit= - returns a
+// paged list of workflow runs backed by DBOS.
+func (h *AsyncHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := workflows.ListFilter{
+		Job:   q.Get("job"),
+		State: q.Get("state"),
+	}
+
+	if sinceStr := q.Get("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid since: %v (expected RFC3339)", err), http.StatusBadRequest)
+			return
+		}
+		filter.Since = since
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit: must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	statuses, err := h.workflowRunner.List(r.Context(), filter)
+	if err != nil {
+		log.Printf("Failed to list workflows: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to list workflows: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"runs": statuses})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/tendant/simple-content-pipeline/internal/deadletter"
+	"github.com/tendant/simple-content-pipeline/internal/workflows"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// DeadLetterHandler serves GET /v1/deadletters and POST
+// /v1/deadletters/{runID}/requeue, so an operator can inspect and resubmit
+// runs that exhausted their retry policy (see WorkflowRunner.RegisterWithPolicy
+// and WorkflowRunner.SetDeadLetterStore).
+type DeadLetterHandler struct {
+	workflowRunner *workflows.WorkflowRunner
+	store          deadletter.Store
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler.
+func NewDeadLetterHandler(runner *workflows.WorkflowRunner, store deadletter.Store) *DeadLetterHandler {
+	return &DeadLetterHandler{workflowRunner: runner, store: store}
+}
+
+// HandleList handles GET /v1/deadletters - lists every dead-lettered run.
+func (h *DeadLetterHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries, err := h.store.List(r.Context())
+	if err != nil {
+		log.Printf("Failed to list dead letters: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to list dead letters: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"dead_letters": entries})
+}
+
+// HandleRequeue handles POST /v1/deadletters/{runID}/requeue - resubmits a
+// dead-lettered run's original ProcessRequest as a fresh run, then removes
+// the dead-letter entry so the run doesn't linger after being handled.
+func (h *DeadLetterHandler) HandleRequeue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	runID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/deadletters/"), "/requeue")
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	entry, ok, err := h.store.Get(r.Context(), runID)
+	if err != nil {
+		log.Printf("Failed to load dead letter %s: %v", runID, err)
+		http.Error(w, fmt.Sprintf("Failed to load dead letter: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "Dead letter not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Requeueing dead letter: run_id=%s, content_id=%s, job=%s", runID, entry.Request.ContentID, entry.Job)
+
+	newRunID, err := h.workflowRunner.RequeueAsync(r.Context(), entry.Request)
+	if err != nil {
+		log.Printf("Failed to requeue dead letter %s: %v", runID, err)
+		http.Error(w, fmt.Sprintf("Failed to requeue: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), runID); err != nil {
+		log.Printf("Warning: Failed to delete dead letter %s after requeue: %v (continuing anyway)", runID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(pipeline.ProcessResponse{RunID: newRunID})
+}
+
+// HandleDeadLetters dispatches /v1/deadletters/{runID}/requeue to
+// HandleRequeue, since it shares the path prefix with HandleList's plain
+// /v1/deadletters route.
+func (h *DeadLetterHandler) HandleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/requeue") {
+		h.HandleRequeue(w, r)
+		return
+	}
+	h.HandleList(w, r)
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/internal/progress"
+	"github.com/tendant/simple-content-pipeline/internal/workflows"
+	"github.com/tendant/simple-content-pipeline/pkg/runlogs"
+)
+
+// handleEvents handles GET /v1/runs/{run_id}/events - streams progress
+// events for the run, one message per event, in the style of Docker's
+// pull/push output: {"id":"<run_id>","status":"Downloading",
+// "progressDetail":{"current":N,"total":M}}. The buffered backlog is sent
+// first so late subscribers still see the tail.
+//
+// The default encoding is newline-delimited JSON (Content-Type
+// application/x-ndjson), one object per line, suitable for a CLI client
+// reading line by line. A browser client using EventSource sends
+// "Accept: text/event-stream", which switches the same messages to SSE
+// "data: <json>\n\n" frames instead.
+//
+// The stream closes once the run reaches a terminal state, the same as
+// handleStatusStream, not just on client disconnect.
+func (h *AsyncHandler) handleEvents(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+
+	backlog, events, cancel := h.workflowRunner.Events(runID)
+	defer cancel()
+
+	// Nothing closes a run's progress-event channel when the workflow
+	// finishes (see progress.Registry) - it's keyed by run ID, not tied to
+	// the workflow's lifecycle, so a client that stays connected past
+	// completion would otherwise idle forever instead of the stream ending
+	// like handleStatusStream's does. Race the event channel against
+	// SubscribeStatus's terminal signal and return when that fires. A nil
+	// statuses channel (standalone mode, no DBOS runtime) just blocks
+	// forever in the select below, leaving today's disconnect-only behavior
+	// unchanged there.
+	var statuses <-chan workflows.WorkflowStatus
+	if s, err := h.workflowRunner.SubscribeStatus(r.Context(), runID); err == nil {
+		statuses = s
+	}
+
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	writeFrame := writeNDJSONFrame
+	if sse {
+		writeFrame = writeSSEFrame
+	}
+
+	for _, ev := range backlog {
+		if !writeFrame(w, dockerStyleFrame(runID, ev)) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if !writeFrame(w, dockerStyleFrame(runID, ev)) {
+				return
+			}
+			flusher.Flush()
+		case status, ok := <-statuses:
+			if !ok {
+				// SubscribeStatus closes after delivering a terminal state;
+				// nil the channel so the next loop iteration blocks here
+				// instead of spinning on a closed channel.
+				statuses = nil
+				continue
+			}
+			if status.State == "succeeded" || status.State == "failed" ||
+				status.State == "cancelled" || status.State == "dead_letter" {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// statusStreamHeartbeat is how often handleStatusStream sends an SSE
+// comment line while waiting for the run's state to change, so a
+// reverse proxy doesn't time out an idle-looking connection.
+const statusStreamHeartbeat = 15 * time.Second
+
+// handleStatusStream handles GET /v1/runs/{run_id}/events?type=status -
+// streams the run's DBOS state (queued/running/succeeded/failed) as SSE
+// until a terminal state is reached, then closes. It shares the /events
+// path with handleEvents (which streams intra-run progress phases like
+// "download"/"resize") rather than claiming a new one, the same way
+// handleLogs's ?follow=1 switches behavior on GET /v1/runs/{run_id}/logs
+// instead of adding another path - `handleStatus` already owns the bare
+// run_id path for a single poll, so the streaming form lives alongside the
+// other /events variant.
+//
+// Reconnecting clients send back the last "id:" they saw via the
+// Last-Event-ID header; since each state is only ever sent once
+// (SubscribeStatus coalesces), a reconnect that already saw the current
+// state just waits for the next one - unless the current state is already
+// terminal, in which case it's resent so a client that disconnected right
+// before receiving it doesn't miss it.
+func (h *AsyncHandler) handleStatusStream(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	statuses, err := h.workflowRunner.SubscribeStatus(r.Context(), runID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to subscribe to workflow status: %v", err), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	first := true
+
+	heartbeat := time.NewTicker(statusStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case status, ok := <-statuses:
+			if !ok {
+				return
+			}
+			if first {
+				first = false
+				if status.State == lastEventID && status.State != "succeeded" && status.State != "failed" &&
+					status.State != "cancelled" && status.State != "dead_letter" {
+					continue
+				}
+			}
+			if !writeSSEFrameWithID(w, status.State, statusStreamFrame(status)) {
+				return
+			}
+			flusher.Flush()
+			if status.State == "succeeded" || status.State == "failed" ||
+				status.State == "cancelled" || status.State == "dead_letter" {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// statusFrame is one message of a /v1/runs/{run_id}/events?type=status
+// stream. Its "id" field doubles as the SSE event ID so a reconnecting
+// client's Last-Event-ID is the state it last saw.
+type statusFrame struct {
+	ID              string  `json:"id"`
+	RunID           string  `json:"run_id"`
+	State           string  `json:"state"`
+	Error           string  `json:"error,omitempty"`
+	CurrentStep     string  `json:"current_step,omitempty"`
+	StepIndex       int     `json:"step_index,omitempty"`
+	StepCount       int     `json:"step_count,omitempty"`
+	Message         string  `json:"message,omitempty"`
+	PercentComplete float64 `json:"percent_complete,omitempty"`
+}
+
+func statusStreamFrame(status workflows.WorkflowStatus) statusFrame {
+	frame := statusFrame{
+		ID:              status.State,
+		RunID:           status.RunID,
+		State:           status.State,
+		CurrentStep:     status.CurrentStep,
+		StepIndex:       status.StepIndex,
+		StepCount:       status.StepCount,
+		Message:         status.Message,
+		PercentComplete: status.PercentComplete,
+	}
+	if status.Error != nil {
+		frame.Error = status.Error.Error()
+	}
+	return frame
+}
+
+// progressDetail mirrors Docker's pull/push progressDetail object: the raw
+// byte counts behind a status line's percentage, when known.
+type progressDetail struct {
+	Current int64 `json:"current"`
+	Total   int64 `json:"total"`
+}
+
+// eventFrame is one line of a /v1/runs/{run_id}/events stream, shaped after
+// Docker's pull/push output (e.g. {"id":"...","status":"Downloading",
+// "progressDetail":{"current":1024,"total":4096}}).
+type eventFrame struct {
+	ID             string          `json:"id"`
+	Status         string          `json:"status"`
+	ProgressDetail *progressDetail `json:"progressDetail,omitempty"`
+}
+
+// dockerStyleFrame translates a progress.Event into the wire format above.
+// ProgressDetail is only populated when the event carries known byte
+// counts (see progress.Sink.EmitBytes); a phase-only event like "decode" or
+// "resize" becomes a bare {"id":...,"status":"..."}.
+func dockerStyleFrame(runID string, ev progress.Event) eventFrame {
+	frame := eventFrame{ID: runID, Status: statusForPhase(ev)}
+	if ev.Total > 0 || ev.Current > 0 {
+		frame.ProgressDetail = &progressDetail{Current: ev.Current, Total: ev.Total}
+	}
+	return frame
+}
+
+// phaseStatus maps a workflow's phase names to the human status line Docker
+// convention would show for it. A phase not listed here (or a 100%-complete
+// terminal report) falls back to ev.Message, then to the capitalized phase
+// name.
+var phaseStatus = map[string]string{
+	"download": "Downloading",
+	"decode":   "Decoding",
+	"resize":   "Resizing",
+	"encode":   "Encoding JPEG",
+	"upload":   "Uploading",
+}
+
+func statusForPhase(ev progress.Event) string {
+	if status, ok := phaseStatus[ev.Phase]; ok && ev.Pct < 100 {
+		return status
+	}
+	if ev.Message != "" {
+		return ev.Message
+	}
+	return capitalize(ev.Phase)
+}
+
+// capitalize upper-cases phase's first rune, leaving the rest unchanged
+// (phase names are short, single-word identifiers like "download").
+func capitalize(phase string) string {
+	if phase == "" {
+		return phase
+	}
+	return strings.ToUpper(phase[:1]) + phase[1:]
+}
+
+// writeNDJSONFrame writes v as a single newline-delimited JSON line.
+func writeNDJSONFrame(w http.ResponseWriter, v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to marshal event frame: %v", err)
+		return true
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err == nil
+}
+
+// writeSSEFrame writes v as a single "data: <json>\n\n" SSE frame.
+func writeSSEFrame(w http.ResponseWriter, v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to marshal SSE frame: %v", err)
+		return true
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err == nil
+}
+
+// handleLogs handles GET /v1/runs/{run_id}/logs - returns the buffered log
+// lines for the run. With ?follow=1 it hijacks the connection and tails new
+// lines as they're published, closing only when the client disconnects.
+func (h *AsyncHandler) handleLogs(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+
+	backlog, lines, cancel := h.workflowRunner.Logs(runID)
+	defer cancel()
+
+	if r.URL.Query().Get("follow") != "1" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		for _, line := range backlog {
+			fmt.Fprintln(w, line)
+		}
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, bufrw, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("hijack failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	bufrw.WriteString("HTTP/1.1 200 OK\r\nContent-Type: text/plain; charset=utf-8\r\nTransfer-Encoding: chunked\r\n\r\n")
+	bufrw.Flush()
+
+	for _, line := range backlog {
+		if !writeLogChunk(bufrw, line) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			if !writeLogChunk(bufrw, line) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleLogArchive handles GET /v1/runs/{run_id}/logs.tar.gz - downloads a
+// self-contained tar.gz of the run's persisted log directory (see
+// runlogs.RunLogger/RunLogArchiver), for debugging a specific run outside
+// the worker's own stdout.
+func (h *AsyncHandler) handleLogArchive(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+	if h.logArchiver == nil || !h.logArchiver.Exists(runID) {
+		http.Error(w, "run logs not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", runID+"-logs.tar.gz"))
+	w.WriteHeader(http.StatusOK)
+
+	if err := h.logArchiver.WriteArchive(w, runID); err != nil && !errors.Is(err, runlogs.ErrRunNotFound) {
+		log.Printf("Failed to write log archive for run_id=%s: %v", runID, err)
+	}
+}
+
+// writeSSEFrameWithID writes v as a "id: <id>\ndata: <json>\n\n" SSE frame,
+// so a reconnecting EventSource's Last-Event-ID header carries id.
+func writeSSEFrameWithID(w http.ResponseWriter, id string, v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to marshal SSE frame: %v", err)
+		return true
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", id, data)
+	return err == nil
+}
+
+// writeLogChunk writes line as one HTTP/1.1 chunked-encoding chunk.
+func writeLogChunk(bufrw *bufio.ReadWriter, line string) bool {
+	chunk := line + "\n"
+	if _, err := fmt.Fprintf(bufrw, "%x\r\n%s\r\n", len(chunk), chunk); err != nil {
+		return false
+	}
+	return bufrw.Flush() == nil
+}
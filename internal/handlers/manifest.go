@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/tendant/simple-content-pipeline/internal/workflows"
+)
+
+// ManifestHandler serves the manifest-driven workflow registry: uploading
+// new WorkflowSpecs at runtime and listing what's currently registered,
+// as an alternative to hard-coding workflow types in main.go.
+type ManifestHandler struct {
+	registry *workflows.ManifestRegistry
+	runner   *workflows.WorkflowRunner
+}
+
+// NewManifestHandler creates a new manifest handler.
+func NewManifestHandler(registry *workflows.ManifestRegistry, runner *workflows.WorkflowRunner) *ManifestHandler {
+	return &ManifestHandler{registry: registry, runner: runner}
+}
+
+// HandleUpload handles POST /v1/workflows/manifests - compiles and
+// registers a WorkflowSpec from the request body (YAML or JSON; both decode
+// the same way) without requiring a restart.
+func (h *ManifestHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	spec, err := workflows.ParseWorkflowSpec(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid workflow spec: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.registry.Load(h.runner, spec); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load workflow spec: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("✓ Registered manifest workflow via API: job=%s", spec.Job)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(spec)
+}
+
+// HandleList handles GET /v1/workflows/manifests - lists every
+// manifest-driven workflow spec currently registered.
+func (h *ManifestHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"specs": h.registry.List(),
+	})
+}
+
+// HandleManifests dispatches /v1/workflows/manifests by method, since
+// upload and list share the same path.
+func (h *ManifestHandler) HandleManifests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleUpload(w, r)
+	case http.MethodGet:
+		h.HandleList(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
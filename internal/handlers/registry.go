@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tendant/simple-content-pipeline/internal/dbosruntime"
+)
+
+// RegistryHandler serves the workflow registry over HTTP so Python (or any
+// other language's) workers can discover callable workflows and their
+// expected inputs at startup, instead of hard-coding field names that
+// happen to match what the Go side sends.
+type RegistryHandler struct {
+	registry *dbosruntime.WorkflowRegistry
+}
+
+// NewRegistryHandler creates a new registry handler.
+func NewRegistryHandler(registry *dbosruntime.WorkflowRegistry) *RegistryHandler {
+	return &RegistryHandler{registry: registry}
+}
+
+// registeredWorkflow is the wire format for a registered workflow: the same
+// information as dbosruntime.WorkflowDefinition, minus the InputBuilder
+// func, which has no useful JSON representation.
+type registeredWorkflow struct {
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version"`
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+}
+
+// HandleList handles GET /v1/workflows - lists registered workflows and
+// their input schemas.
+func (h *RegistryHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	defs := h.registry.List()
+	out := make([]registeredWorkflow, 0, len(defs))
+	for _, def := range defs {
+		out = append(out, registeredWorkflow{
+			Name:        def.Name,
+			Version:     def.Version,
+			InputSchema: def.InputSchema,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workflows": out,
+	})
+}
@@ -0,0 +1,344 @@
+package resumable
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const tusVersion = "1.0.0"
+
+// Handler implements the tus 1.0 core protocol (creation, head, patch,
+// termination extensions) against a Store for bookkeeping and a
+// stagingDir for the bytes themselves. Register its methods at
+// POST/HEAD/PATCH/DELETE on a base path, e.g.:
+//
+//	mux.HandleFunc("/api/uploads", h.HandleCreate)
+//	mux.HandleFunc("/api/uploads/", h.HandleItem)
+type Handler struct {
+	store    Store
+	staging  *stagingDir
+	uploader *Uploader
+	cfg      Config
+	basePath string
+	locks    *uploadLocks
+}
+
+// NewHandler creates a Handler serving uploads under basePath (e.g.
+// "/api/uploads", no trailing slash), staging in-progress bytes under dir.
+func NewHandler(store Store, uploader *Uploader, dir string, basePath string, cfg Config) (*Handler, error) {
+	staging, err := newStagingDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		store:    store,
+		staging:  staging,
+		uploader: uploader,
+		cfg:      cfg,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		locks:    newUploadLocks(),
+	}, nil
+}
+
+// HandleCreate handles POST {basePath} - creates a new upload and returns
+// its Location.
+func (h *Handler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "Upload-Length header is required", http.StatusBadRequest)
+		return
+	}
+	if h.cfg.MaxUploadSize > 0 && length > h.cfg.MaxUploadSize {
+		http.Error(w, fmt.Sprintf("Upload-Length exceeds maximum of %d bytes", h.cfg.MaxUploadSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	meta := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+
+	id := uuid.NewString()
+	now := time.Now()
+	upload := &Upload{
+		ID:           id,
+		Length:       length,
+		OwnerID:      r.Header.Get("X-Owner-Id"),
+		TenantID:     r.Header.Get("X-Tenant-Id"),
+		FileName:     meta["filename"],
+		MimeType:     meta["filetype"],
+		CreatedAt:    now,
+		LastActivity: now,
+	}
+
+	if err := h.staging.create(id); err != nil {
+		log.Printf("resumable: failed to stage upload: %v", err)
+		http.Error(w, "Failed to create upload", http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.Create(r.Context(), upload); err != nil {
+		log.Printf("resumable: failed to persist upload: %v", err)
+		_ = h.staging.remove(id)
+		http.Error(w, "Failed to create upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", h.basePath+"/"+id)
+	w.Header().Set("Upload-Offset", "0")
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HandleItem dispatches {basePath}/{id} requests (HEAD, PATCH, DELETE) to
+// the matching action.
+func (h *Handler) HandleItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	id := strings.TrimPrefix(r.URL.Path, h.basePath+"/")
+	if id == "" {
+		http.Error(w, "upload id is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		h.handleHead(w, r, id)
+	case http.MethodPatch:
+		h.handlePatch(w, r, id)
+	case http.MethodDelete:
+		h.handleDelete(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) handleHead(w http.ResponseWriter, r *http.Request, id string) {
+	upload, err := h.store.Get(r.Context(), id)
+	if err == ErrNotFound {
+		http.Error(w, "Upload not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("resumable: failed to get upload %s: %v", id, err)
+		http.Error(w, "Failed to get upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+
+	// Not part of the tus spec, but it's the only way for a client to learn
+	// the content ID a completed upload produced without a separate
+	// GET-like endpoint for something that's otherwise HEAD-only.
+	if upload.Completed {
+		w.Header().Set("X-Content-Id", upload.ContentID)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) handlePatch(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type must be application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Upload-Offset header is required", http.StatusBadRequest)
+		return
+	}
+
+	// Serialize the read-offset/append/advance sequence per upload ID: two
+	// concurrent or retried PATCH requests at the same offset must not both
+	// append to the staging file before either's Advance CAS runs (see
+	// uploadLocks).
+	release := h.locks.lock(id)
+	defer release()
+
+	upload, err := h.store.Get(r.Context(), id)
+	if err == ErrNotFound {
+		http.Error(w, "Upload not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("resumable: failed to get upload %s: %v", id, err)
+		http.Error(w, "Failed to get upload", http.StatusInternalServerError)
+		return
+	}
+	if upload.Completed {
+		http.Error(w, "Upload already completed", http.StatusForbidden)
+		return
+	}
+	if offset != upload.Offset {
+		http.Error(w, fmt.Sprintf("Upload-Offset %d does not match current offset %d", offset, upload.Offset), http.StatusConflict)
+		return
+	}
+
+	// Cap what we actually append at the bytes still needed to reach
+	// Upload-Length, so an over-length chunk can never grow the staging
+	// file past Upload-Length (see finalize's length check). The bytes that
+	// do get appended from a rejected over-length chunk are truncated back
+	// off below.
+	remaining := upload.Length - upload.Offset
+	written, err := h.staging.append(id, io.LimitReader(r.Body, remaining))
+	if err != nil {
+		log.Printf("resumable: failed to append to upload %s: %v", id, err)
+		http.Error(w, "Failed to append chunk", http.StatusInternalServerError)
+		return
+	}
+
+	if written == remaining {
+		// The client may have sent more than remaining bytes; io.LimitReader
+		// silently discards the rest rather than erroring, so check for a
+		// leftover byte that never made it into the staging file. The
+		// remaining bytes we DID append are real bytes the upload still
+		// needs, but we're about to reject this request without advancing
+		// the store's offset to match them - truncate the file back down so
+		// a subsequent retry starting from the same (unchanged) offset
+		// doesn't double them up.
+		var probe [1]byte
+		if n, _ := io.ReadFull(r.Body, probe[:]); n > 0 {
+			if err := h.staging.truncate(id, upload.Offset); err != nil {
+				log.Printf("resumable: failed to truncate staging file for upload %s: %v", id, err)
+			}
+			http.Error(w, "chunk extends past Upload-Length", http.StatusBadRequest)
+			return
+		}
+	}
+
+	hash, err := h.staging.resumeHash(id)
+	if err != nil {
+		log.Printf("resumable: failed to hash upload %s: %v", id, err)
+		http.Error(w, "Failed to process chunk", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.store.Advance(r.Context(), id, offset, written, hash); err != nil {
+		if err == ErrOffsetMismatch {
+			http.Error(w, "Upload-Offset does not match current offset", http.StatusConflict)
+			return
+		}
+		log.Printf("resumable: failed to advance upload %s: %v", id, err)
+		http.Error(w, "Failed to record chunk", http.StatusInternalServerError)
+		return
+	}
+
+	newOffset := offset + written
+	w.Header().Set("Upload-Offset", strconv.FormatInt(newOffset, 10))
+
+	if newOffset < upload.Length {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Final chunk: hand the staged bytes to simple-content. The staged file
+	// is piped into Uploader.Upload rather than read fully into memory
+	// first, so finishing a large upload doesn't double its memory
+	// footprint.
+	contentID, err := h.finalize(r.Context(), id, upload)
+	if err != nil {
+		log.Printf("resumable: failed to finalize upload %s: %v", id, err)
+		http.Error(w, fmt.Sprintf("Failed to finalize upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Content-Id", contentID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finalize opens id's complete staged file and pipes it into
+// h.uploader.Upload, so the upload goes through as a single streamed
+// UploadContent call instead of an io.ReadAll into a byte slice. On
+// success the staging file is removed (its bytes now live in
+// simple-content) and the upload is marked complete.
+func (h *Handler) finalize(ctx context.Context, id string, upload *Upload) (string, error) {
+	staged, err := h.staging.open(id)
+	if err != nil {
+		return "", err
+	}
+	defer staged.Close()
+
+	// Guard against a corrupted staging file (e.g. from a bug elsewhere in
+	// the append/advance sequence) reaching simple-content as if it were a
+	// complete, correctly-sized upload.
+	info, err := staged.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat staged upload %s: %w", id, err)
+	}
+	if info.Size() != upload.Length {
+		return "", fmt.Errorf("staged upload %s has %d bytes, want %d", id, info.Size(), upload.Length)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, copyErr := io.Copy(pw, staged)
+		pw.CloseWithError(copyErr)
+	}()
+
+	contentID, err := h.uploader.Upload(ctx, upload, pr)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.store.Complete(ctx, id, contentID); err != nil {
+		return "", fmt.Errorf("upload succeeded but failed to record completion: %w", err)
+	}
+	if err := h.staging.remove(id); err != nil {
+		log.Printf("resumable: failed to remove staged file for completed upload %s: %v", id, err)
+	}
+	h.locks.forget(id)
+
+	return contentID, nil
+}
+
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	// Same lock as handlePatch: without it, a DELETE could remove the
+	// staging file out from under a PATCH that's mid-append.
+	release := h.locks.lock(id)
+	defer release()
+
+	if err := h.store.Delete(r.Context(), id); err != nil {
+		log.Printf("resumable: failed to delete upload %s: %v", id, err)
+		http.Error(w, "Failed to delete upload", http.StatusInternalServerError)
+		return
+	}
+	if err := h.staging.remove(id); err != nil {
+		log.Printf("resumable: failed to remove staged upload %s: %v", id, err)
+	}
+	h.locks.forget(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseUploadMetadata decodes a tus Upload-Metadata header
+// ("key base64val,key2 base64val2") into a plain string map.
+func parseUploadMetadata(header string) map[string]string {
+	meta := make(map[string]string)
+	if header == "" {
+		return meta
+	}
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			continue
+		}
+		meta[parts[0]] = string(decoded)
+	}
+	return meta
+}
@@ -0,0 +1,43 @@
+package resumable
+
+import "sync"
+
+// uploadLocks serializes PATCH processing per upload ID. Without this, two
+// concurrent (or retried) PATCH requests at the same offset both pass
+// handlePatch's offset check, both append their chunk to the on-disk
+// staging file, and only then race on Store.Advance's CAS - the loser gets
+// a 409, but its bytes are already permanently appended to the file with no
+// rollback. Serializing the whole read-offset/append/advance sequence per
+// upload ID closes that window. Each ID gets its own *sync.Mutex, created
+// lazily and kept for the upload's lifetime.
+type uploadLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newUploadLocks() *uploadLocks {
+	return &uploadLocks{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock acquires id's mutex, creating it if this is the first PATCH seen for
+// id, and returns a func to release it.
+func (u *uploadLocks) lock(id string) func() {
+	u.mu.Lock()
+	l, ok := u.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		u.locks[id] = l
+	}
+	u.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// forget drops id's lock entry once the upload is done (completed or
+// deleted), so the map doesn't grow unboundedly for a long-running worker.
+func (u *uploadLocks) forget(id string) {
+	u.mu.Lock()
+	delete(u.locks, id)
+	u.mu.Unlock()
+}
@@ -0,0 +1,93 @@
+package resumable
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store, used by the standalone binary where
+// there's no DBOS Postgres pool to share. Upload state (and therefore
+// resumability) does not survive a restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	uploads map[string]*Upload
+}
+
+// NewMemoryStore creates a new in-memory resumable upload store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uploads: make(map[string]*Upload)}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, upload *Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clone := *upload
+	s.uploads[upload.ID] = &clone
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	clone := *upload
+	return &clone, nil
+}
+
+func (s *MemoryStore) Advance(ctx context.Context, id string, offset int64, length int64, sha256 string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if upload.Offset != offset {
+		return ErrOffsetMismatch
+	}
+	upload.Offset += length
+	upload.SHA256 = sha256
+	upload.LastActivity = time.Now()
+	return nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, id string, contentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[id]
+	if !ok {
+		return ErrNotFound
+	}
+	upload.Completed = true
+	upload.ContentID = contentID
+	upload.LastActivity = time.Now()
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.uploads, id)
+	return nil
+}
+
+func (s *MemoryStore) PurgeStaleBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged []string
+	for id, upload := range s.uploads {
+		if upload.LastActivity.Before(cutoff) {
+			purged = append(purged, id)
+			delete(s.uploads, id)
+		}
+	}
+	return purged, nil
+}
+
+func (s *MemoryStore) StartSweeper(ctx context.Context, cfg Config, onPurge func(id string)) {
+	startSweeper(ctx, cfg, s.PurgeStaleBefore, onPurge)
+}
@@ -0,0 +1,149 @@
+package resumable
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore is a Store backed by a resumable_uploads table, so upload
+// state survives a worker restart. It's the implementation used by the
+// DBOS-backed worker, sharing the DBOS system database pool.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new Postgres-backed resumable upload store,
+// creating the resumable_uploads table if it doesn't already exist.
+func NewPostgresStore(db *sql.DB) (*PostgresStore, error) {
+	store := &PostgresStore{db: db}
+	if err := store.ensureSchema(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure resumable_uploads schema: %w", err)
+	}
+	return store, nil
+}
+
+func (s *PostgresStore) ensureSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS resumable_uploads (
+			id TEXT PRIMARY KEY,
+			length BIGINT NOT NULL,
+			offset_bytes BIGINT NOT NULL DEFAULT 0,
+			owner_id TEXT,
+			tenant_id TEXT,
+			file_name TEXT,
+			mime_type TEXT,
+			content_id TEXT,
+			completed BOOLEAN NOT NULL DEFAULT FALSE,
+			sha256 TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			last_activity TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create resumable_uploads table: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Create(ctx context.Context, upload *Upload) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO resumable_uploads
+			(id, length, offset_bytes, owner_id, tenant_id, file_name, mime_type, sha256, created_at, last_activity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, upload.ID, upload.Length, upload.Offset, upload.OwnerID, upload.TenantID, upload.FileName, upload.MimeType, upload.SHA256, upload.CreatedAt, upload.LastActivity)
+	if err != nil {
+		return fmt.Errorf("failed to create resumable upload: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Upload, error) {
+	upload := &Upload{ID: id}
+	var contentID sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT length, offset_bytes, owner_id, tenant_id, file_name, mime_type, content_id, completed, sha256, created_at, last_activity
+		FROM resumable_uploads WHERE id = $1
+	`, id).Scan(&upload.Length, &upload.Offset, &upload.OwnerID, &upload.TenantID, &upload.FileName, &upload.MimeType, &contentID, &upload.Completed, &upload.SHA256, &upload.CreatedAt, &upload.LastActivity)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resumable upload: %w", err)
+	}
+	upload.ContentID = contentID.String
+	return upload, nil
+}
+
+func (s *PostgresStore) Advance(ctx context.Context, id string, offset int64, length int64, sha256 string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE resumable_uploads
+		SET offset_bytes = offset_bytes + $1, sha256 = $2, last_activity = NOW()
+		WHERE id = $3 AND offset_bytes = $4
+	`, length, sha256, id, offset)
+	if err != nil {
+		return fmt.Errorf("failed to advance resumable upload: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to confirm resumable upload advance: %w", err)
+	}
+	if rows == 0 {
+		if _, getErr := s.Get(ctx, id); getErr != nil {
+			return getErr
+		}
+		return ErrOffsetMismatch
+	}
+	return nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, id string, contentID string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE resumable_uploads
+		SET completed = TRUE, content_id = $1, last_activity = NOW()
+		WHERE id = $2
+	`, contentID, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete resumable upload: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to confirm resumable upload completion: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM resumable_uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete resumable upload: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) PurgeStaleBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		DELETE FROM resumable_uploads WHERE last_activity < $1 RETURNING id
+	`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge stale resumable uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var purged []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan purged resumable upload id: %w", err)
+		}
+		purged = append(purged, id)
+	}
+	return purged, rows.Err()
+}
+
+func (s *PostgresStore) StartSweeper(ctx context.Context, cfg Config, onPurge func(id string)) {
+	startSweeper(ctx, cfg, s.PurgeStaleBefore, onPurge)
+}
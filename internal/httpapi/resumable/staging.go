@@ -0,0 +1,105 @@
+package resumable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// stagingDir holds the not-yet-complete bytes of every in-progress upload,
+// one file per upload ID. It's deliberately local disk rather than
+// Postgres: Store only needs to persist small per-upload bookkeeping
+// (offset, length, hash), not the upload bytes themselves, and a worker
+// restart only needs the directory to still be there - not a live
+// connection - to pick a PATCH back up.
+type stagingDir struct {
+	dir string
+}
+
+func newStagingDir(dir string) (*stagingDir, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create resumable staging dir %s: %w", dir, err)
+	}
+	return &stagingDir{dir: dir}, nil
+}
+
+func (s *stagingDir) path(id string) string {
+	return filepath.Join(s.dir, id+".part")
+}
+
+// create makes an empty staging file for a new upload.
+func (s *stagingDir) create(id string) error {
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create staging file for upload %s: %w", id, err)
+	}
+	return f.Close()
+}
+
+// append writes r to the end of id's staging file, returning how many
+// bytes were written. The caller is responsible for checking the resulting
+// total against the upload's declared Length.
+func (s *stagingDir) append(id string, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(s.path(id), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open staging file for upload %s: %w", id, err)
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, fmt.Errorf("failed to append to staging file for upload %s: %w", id, err)
+	}
+	return n, nil
+}
+
+// truncate resets id's staging file back down to size bytes, undoing an
+// append whose bytes turned out not to be usable (e.g. an over-length
+// chunk) before the store's offset was advanced to match it.
+func (s *stagingDir) truncate(id string, size int64) error {
+	if err := os.Truncate(s.path(id), size); err != nil {
+		return fmt.Errorf("failed to truncate staging file for upload %s: %w", id, err)
+	}
+	return nil
+}
+
+// open returns a reader over id's complete staged bytes, for handing off
+// to contentService.UploadContent once the upload reaches its declared
+// Length.
+func (s *stagingDir) open(id string) (*os.File, error) {
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open staged upload %s: %w", id, err)
+	}
+	return f, nil
+}
+
+// remove deletes id's staging file. Removing an unknown id is not an
+// error, matching Store.Delete's idempotent semantics.
+func (s *stagingDir) remove(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove staging file for upload %s: %w", id, err)
+	}
+	return nil
+}
+
+// resumeHash recomputes the running sha256 of the bytes already staged for
+// id. A fresh process has no in-memory running hash.Hash left over from
+// before it restarted, so a PATCH that resumes a partially-uploaded file
+// re-derives the hash from disk once instead of trusting stale state.
+func (s *stagingDir) resumeHash(id string) (string, error) {
+	f, err := s.open(id)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash staged upload %s: %w", id, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
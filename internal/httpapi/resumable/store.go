@@ -0,0 +1,74 @@
+package resumable
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Get when id has never been created, has
+// been deleted, or was purged by the inactivity sweeper.
+var ErrNotFound = errors.New("resumable: upload not found")
+
+// ErrOffsetMismatch is returned by Store.Advance when the caller's expected
+// offset doesn't match the persisted one, mirroring the tus 409 Conflict
+// response for a stale PATCH (e.g. a retried chunk after a partial write).
+var ErrOffsetMismatch = errors.New("resumable: offset mismatch")
+
+// Config bounds a resumable upload subsystem: the largest upload it will
+// accept, and how long an upload may sit idle before the sweeper purges it
+// and its staged bytes.
+type Config struct {
+	// MaxUploadSize rejects Upload-Length (and cumulative PATCH writes)
+	// above this many bytes. Zero means unbounded.
+	MaxUploadSize int64
+
+	// TTL is how long an upload may go without a recorded PATCH before
+	// StartSweeper purges it; a HEAD does not refresh LastActivity. Zero
+	// disables TTL-based expiry.
+	TTL time.Duration
+
+	// SweepInterval is how often StartSweeper checks for expired uploads.
+	// Zero disables the background sweeper even if TTL is set.
+	SweepInterval time.Duration
+}
+
+// Store persists resumable upload state (offset, length, owner/tenant,
+// running hash, and the resulting content ID once complete) so an upload
+// survives a worker restart mid-transfer. PostgresStore backs it with the
+// DBOS system database; MemoryStore backs it with an in-process map for
+// standalone mode, same split as dedupe.Tracker.
+type Store interface {
+	// Create persists a new upload. ID, CreatedAt, and LastActivity must
+	// already be set by the caller.
+	Create(ctx context.Context, upload *Upload) error
+
+	// Get returns the upload state for id, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Upload, error)
+
+	// Advance records that length bytes have been appended since the last
+	// Advance/Create, moving the persisted offset from offset to
+	// offset+length and updating sha256 and LastActivity. It returns
+	// ErrOffsetMismatch if the upload's persisted offset isn't offset,
+	// so a caller never double-applies (or misses) a chunk.
+	Advance(ctx context.Context, id string, offset int64, length int64, sha256 string) error
+
+	// Complete marks id's upload finished and records the content ID
+	// simple-content assigned it.
+	Complete(ctx context.Context, id string, contentID string) error
+
+	// Delete removes id's upload state. Deleting an unknown id is not an
+	// error, matching tus's idempotent DELETE semantics.
+	Delete(ctx context.Context, id string) error
+
+	// PurgeStaleBefore deletes uploads whose LastActivity is older than
+	// cutoff and returns the deleted IDs, so the caller can also remove
+	// their staged files.
+	PurgeStaleBefore(ctx context.Context, cutoff time.Time) ([]string, error)
+
+	// StartSweeper launches a background purge loop driven by cfg.TTL and
+	// cfg.SweepInterval, until ctx is cancelled. A no-op if either is zero.
+	// onPurge is called with each purged ID (e.g. to remove its staged
+	// file); it may be nil.
+	StartSweeper(ctx context.Context, cfg Config, onPurge func(id string))
+}
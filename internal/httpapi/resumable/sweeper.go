@@ -0,0 +1,43 @@
+package resumable
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// startSweeper runs the shared sweep loop both Store implementations use:
+// every cfg.SweepInterval, purge uploads inactive since cfg.TTL ago and
+// report each purged ID to onPurge. A no-op if TTL or SweepInterval is zero.
+func startSweeper(ctx context.Context, cfg Config, purgeBefore func(ctx context.Context, cutoff time.Time) ([]string, error), onPurge func(id string)) {
+	if cfg.TTL <= 0 || cfg.SweepInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cutoff := time.Now().Add(-cfg.TTL)
+				purged, err := purgeBefore(ctx, cutoff)
+				if err != nil {
+					log.Printf("resumable upload sweep failed: %v", err)
+					continue
+				}
+				for _, id := range purged {
+					if onPurge != nil {
+						onPurge(id)
+					}
+				}
+				if len(purged) > 0 {
+					log.Printf("resumable upload sweep purged %d upload(s) inactive since before %s", len(purged), cutoff.Format(time.RFC3339))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,35 @@
+// Package resumable implements a tus 1.0 (https://tus.io/protocol/resumable-upload)
+// resumable upload subsystem: POST creates an upload, PATCH appends bytes to
+// it (so a connection blip only loses the in-flight chunk, not the whole
+// transfer), HEAD reports progress, and DELETE cancels. Per-upload state is
+// persisted so an in-progress upload survives a worker restart; only the
+// staged bytes on disk need the process to come back up pointed at the same
+// --resumable-dir.
+package resumable
+
+import "time"
+
+// Upload is the persisted state of one resumable upload.
+type Upload struct {
+	ID       string
+	Length   int64
+	Offset   int64
+	OwnerID  string
+	TenantID string
+	FileName string
+	MimeType string
+
+	// ContentID is the simple-content ID produced once the upload
+	// completes and is handed to contentService.UploadContent. Empty until
+	// Completed is true.
+	ContentID string
+	Completed bool
+
+	// SHA256 is the running hex-encoded digest of the bytes received so
+	// far, recomputed from the staged file if the process restarts
+	// mid-upload (see staging.go's resumeHash).
+	SHA256 string
+
+	CreatedAt    time.Time
+	LastActivity time.Time
+}
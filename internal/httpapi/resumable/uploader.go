@@ -0,0 +1,71 @@
+package resumable
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/tendant/simple-content/pkg/simplecontent"
+)
+
+// defaultOwnerID and defaultTenantID match the demo IDs the
+// application-hook example has always uploaded under. They're used when a
+// Create request doesn't supply X-Owner-Id/X-Tenant-Id, so single-tenant
+// deployments don't have to set them at all.
+var (
+	defaultOwnerID  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	defaultTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+// Uploader hands a completed upload's bytes to simple-content. It's a thin
+// wrapper around simplecontent.Service.UploadContent so Handler doesn't
+// need the full Service interface in its own signature.
+type Uploader struct {
+	service simplecontent.Service
+}
+
+// NewUploader creates an Uploader backed by an embedded simple-content
+// service. There is no HTTP-API-backed variant: a resumable upload needs a
+// streaming UploadContent call, which the simple-content HTTP client
+// doesn't expose, so this subsystem is only wired up in embedded mode (see
+// cmd/pipeline-worker/main.go).
+func NewUploader(service simplecontent.Service) *Uploader {
+	return &Uploader{service: service}
+}
+
+// Upload streams r into simple-content as new content owned by
+// upload.OwnerID/TenantID (falling back to defaultOwnerID/defaultTenantID
+// if unset), returning the resulting content ID.
+func (u *Uploader) Upload(ctx context.Context, upload *Upload, r io.Reader) (string, error) {
+	ownerID, tenantID := defaultOwnerID, defaultTenantID
+	if upload.OwnerID != "" {
+		id, err := uuid.Parse(upload.OwnerID)
+		if err != nil {
+			return "", fmt.Errorf("invalid owner ID: %w", err)
+		}
+		ownerID = id
+	}
+	if upload.TenantID != "" {
+		id, err := uuid.Parse(upload.TenantID)
+		if err != nil {
+			return "", fmt.Errorf("invalid tenant ID: %w", err)
+		}
+		tenantID = id
+	}
+
+	content, err := u.service.UploadContent(ctx, simplecontent.UploadContentRequest{
+		OwnerID:      ownerID,
+		TenantID:     tenantID,
+		Name:         upload.FileName,
+		DocumentType: upload.MimeType,
+		Reader:       r,
+		FileName:     upload.FileName,
+		Tags:         []string{"uploaded", "via-resumable"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload content: %w", err)
+	}
+
+	return content.ID.String(), nil
+}
@@ -0,0 +1,61 @@
+// Package ocr provides text recognition backends for the OCR workflow.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// defaultLanguage is used when TesseractRecognizer.Lang is unset.
+const defaultLanguage = "eng"
+
+// TesseractRecognizer extracts text from image content by shelling out to
+// the Tesseract OCR CLI. It requires the `tesseract` binary to be on PATH.
+type TesseractRecognizer struct {
+	// Lang is the Tesseract language pack to use (e.g. "eng", "deu").
+	// Defaults to "eng" if unset.
+	Lang string
+}
+
+// NewTesseractRecognizer creates a recognizer that runs Tesseract with the
+// given language pack (empty defaults to "eng").
+func NewTesseractRecognizer(lang string) *TesseractRecognizer {
+	return &TesseractRecognizer{Lang: lang}
+}
+
+// Recognize writes r to a temp file and runs `tesseract <file> stdout`,
+// returning the recognized text.
+func (t *TesseractRecognizer) Recognize(ctx context.Context, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp("", "ocr-input-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	lang := t.Lang
+	if lang == "" {
+		lang = defaultLanguage
+	}
+
+	cmd := exec.CommandContext(ctx, "tesseract", tmp.Name(), "stdout", "-l", lang)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %w (stderr: %s)", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,328 @@
+// Package progress provides a per-run event and log fan-out so long-running
+// workflows can report progress to callers that poll or stream, instead of
+// giving them nothing until the workflow returns.
+//
+// Each run gets a ring buffer plus a set of subscriber channels: late
+// subscribers (e.g. a client that connects after a workflow has already
+// emitted a few events) see the buffered tail, and live subscribers get
+// incremental updates as they're published.
+package progress
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultEventBacklog and defaultLogBacklog cap how many events/log lines
+// are retained per run for subscribers that connect late.
+const (
+	defaultEventBacklog = 100
+	defaultLogBacklog   = 500
+)
+
+// subscriberBuffer bounds how many pending messages a slow subscriber can
+// fall behind by before being dropped, so one stuck client can't block
+// publishing for everyone else.
+const subscriberBuffer = 32
+
+// Event is a single progress update emitted by a workflow, e.g.
+// {"phase":"download","pct":42.5,"message":"...", "ts":"..."}.
+type Event struct {
+	Phase   string    `json:"phase"`
+	Pct     float64   `json:"pct"`
+	Message string    `json:"message,omitempty"`
+	TS      time.Time `json:"ts"`
+
+	// Current and Total carry the raw byte counts behind Pct, when known
+	// (see EmitBytes), so a consumer that wants to render its own progress
+	// bar (or a Docker-pull-style progressDetail) doesn't have to recover
+	// them from a percentage. Zero when EmitBytes wasn't used to produce
+	// this event.
+	Current int64 `json:"current,omitempty"`
+	Total   int64 `json:"total,omitempty"`
+}
+
+// Registry owns the per-run event and log streams for every in-flight or
+// recently finished workflow run.
+type Registry struct {
+	events *keyedRing[Event]
+	logs   *keyedRing[string]
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		events: newKeyedRing[Event](defaultEventBacklog),
+		logs:   newKeyedRing[string](defaultLogBacklog),
+	}
+}
+
+// Sink returns a per-run handle workflows use to report progress. Safe to
+// call on a nil *Registry; the returned Sink is then a no-op.
+func (reg *Registry) Sink(runID string) *Sink {
+	return &Sink{runID: runID, registry: reg}
+}
+
+// SubscribeEvents returns the buffered event backlog for runID plus a
+// channel of subsequent events. Call the returned cancel func when done
+// reading to release the subscription.
+func (reg *Registry) SubscribeEvents(runID string) (backlog []Event, ch <-chan Event, cancel func()) {
+	if reg == nil {
+		return nil, nil, func() {}
+	}
+	return reg.events.subscribe(runID)
+}
+
+// SubscribeLogs returns the buffered log backlog for runID plus a channel
+// of subsequent lines. Call the returned cancel func when done reading to
+// release the subscription.
+func (reg *Registry) SubscribeLogs(runID string) (backlog []string, ch <-chan string, cancel func()) {
+	if reg == nil {
+		return nil, nil, func() {}
+	}
+	return reg.logs.subscribe(runID)
+}
+
+// Sink is a per-run handle used by a workflow to emit progress events and
+// log lines as it executes.
+type Sink struct {
+	runID    string
+	registry *Registry
+}
+
+// Emit publishes a phase/percent/message progress update. pct is in
+// [0, 100]; pass a negative value when the total size (and therefore
+// percentage) isn't known.
+func (s *Sink) Emit(phase string, pct float64, message string) {
+	if s == nil || s.registry == nil {
+		return
+	}
+	s.registry.events.publish(s.runID, Event{
+		Phase:   phase,
+		Pct:     pct,
+		Message: message,
+		TS:      time.Now(),
+	})
+}
+
+// EmitBytes is a convenience wrapper around Emit for byte-counted progress
+// (e.g. a download or upload leg). total <= 0 means the total size isn't
+// known, in which case pct is reported as -1 and Total is omitted.
+func (s *Sink) EmitBytes(phase string, sent, total int64, message string) {
+	if s == nil || s.registry == nil {
+		return
+	}
+	pct := -1.0
+	var knownTotal int64
+	if total > 0 {
+		pct = float64(sent) / float64(total) * 100
+		knownTotal = total
+	}
+	s.registry.events.publish(s.runID, Event{
+		Phase:   phase,
+		Pct:     pct,
+		Message: message,
+		TS:      time.Now(),
+		Current: sent,
+		Total:   knownTotal,
+	})
+}
+
+// StageStarted reports that a named stage (e.g. "download", "resize") has
+// begun. Equivalent to Emit(name, 0, "started").
+func (s *Sink) StageStarted(name string) {
+	s.Emit(name, 0, "started")
+}
+
+// StagePercent reports fractional progress within a named stage, e.g. as a
+// long resize loop advances. Equivalent to Emit(name, pct, "").
+func (s *Sink) StagePercent(name string, pct float64) {
+	s.Emit(name, pct, "")
+}
+
+// StageCompleted reports that a named stage has finished, optionally
+// attaching metadata (e.g. {"derived_id": "..."}) that's folded into the
+// event's message for display.
+func (s *Sink) StageCompleted(name string, meta map[string]string) {
+	s.Emit(name, 100, formatStageMeta(meta))
+}
+
+func formatStageMeta(meta map[string]string) string {
+	if len(meta) == 0 {
+		return "completed"
+	}
+	parts := make([]string, 0, len(meta))
+	for k, v := range meta {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// Log appends a structured log line to the run's log stream.
+func (s *Sink) Log(line string) {
+	if s == nil || s.registry == nil {
+		return
+	}
+	s.registry.logs.publish(s.runID, line)
+}
+
+// reportInterval throttles how often Reader emits byte-count progress.
+const reportInterval = 250 * time.Millisecond
+
+// Reader wraps an io.Reader, reporting cumulative bytes read to sink as
+// phase-tagged progress (at most once per reportInterval, plus a final
+// report on EOF). total <= 0 means the size isn't known up front.
+type Reader struct {
+	r     io.Reader
+	sink  *Sink
+	phase string
+	total int64
+	read  int64
+	last  time.Time
+}
+
+// NewReader wraps r so reads are reported to sink under phase, with total
+// bytes if known (pass 0 if not).
+func NewReader(r io.Reader, sink *Sink, phase string, total int64) *Reader {
+	return &Reader{r: r, sink: sink, phase: phase, total: total}
+}
+
+func (pr *Reader) Read(buf []byte) (int, error) {
+	n, err := pr.r.Read(buf)
+	if n > 0 {
+		pr.read += int64(n)
+	}
+	if err == io.EOF || (n > 0 && time.Since(pr.last) >= reportInterval) {
+		pr.sink.EmitBytes(pr.phase, pr.read, pr.total, "")
+		pr.last = time.Now()
+	}
+	return n, err
+}
+
+// Writer wraps an io.Writer, reporting cumulative bytes written to sink as
+// phase-tagged progress (at most once per reportInterval). total <= 0 means
+// the size isn't known up front; call Close when done writing to flush a
+// final report, since unlike Reader there's no EOF to trigger one.
+type Writer struct {
+	w       io.Writer
+	sink    *Sink
+	phase   string
+	total   int64
+	written int64
+	last    time.Time
+}
+
+// NewWriter wraps w so writes are reported to sink under phase, with total
+// bytes if known (pass 0 if not).
+func NewWriter(w io.Writer, sink *Sink, phase string, total int64) *Writer {
+	return &Writer{w: w, sink: sink, phase: phase, total: total}
+}
+
+func (pw *Writer) Write(buf []byte) (int, error) {
+	n, err := pw.w.Write(buf)
+	if n > 0 {
+		pw.written += int64(n)
+	}
+	if n > 0 && time.Since(pw.last) >= reportInterval {
+		pw.sink.EmitBytes(pw.phase, pw.written, pw.total, "")
+		pw.last = time.Now()
+	}
+	return n, err
+}
+
+// Close flushes a final byte-count report for whatever was written. It
+// doesn't close the underlying writer.
+func (pw *Writer) Close() error {
+	pw.sink.EmitBytes(pw.phase, pw.written, pw.total, "")
+	return nil
+}
+
+// keyedRing holds one ring buffer per key, each with its own set of
+// subscriber channels.
+type keyedRing[T any] struct {
+	mu       sync.Mutex
+	capacity int
+	streams  map[string]*ringBuffer[T]
+}
+
+func newKeyedRing[T any](capacity int) *keyedRing[T] {
+	return &keyedRing[T]{
+		capacity: capacity,
+		streams:  make(map[string]*ringBuffer[T]),
+	}
+}
+
+func (k *keyedRing[T]) stream(key string) *ringBuffer[T] {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	rb, ok := k.streams[key]
+	if !ok {
+		rb = newRingBuffer[T](k.capacity)
+		k.streams[key] = rb
+	}
+	return rb
+}
+
+func (k *keyedRing[T]) publish(key string, v T) {
+	k.stream(key).publish(v)
+}
+
+func (k *keyedRing[T]) subscribe(key string) (backlog []T, ch <-chan T, cancel func()) {
+	return k.stream(key).subscribe()
+}
+
+// ringBuffer retains the last `capacity` published values and fans them out
+// to any currently-subscribed channels.
+type ringBuffer[T any] struct {
+	mu   sync.Mutex
+	cap  int
+	buf  []T
+	subs map[chan T]struct{}
+}
+
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	return &ringBuffer[T]{cap: capacity, subs: make(map[chan T]struct{})}
+}
+
+func (rb *ringBuffer[T]) publish(v T) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.buf = append(rb.buf, v)
+	if len(rb.buf) > rb.cap {
+		rb.buf = rb.buf[len(rb.buf)-rb.cap:]
+	}
+
+	for ch := range rb.subs {
+		select {
+		case ch <- v:
+		default:
+			// Slow subscriber; drop rather than block the publisher.
+		}
+	}
+}
+
+func (rb *ringBuffer[T]) subscribe() (backlog []T, ch <-chan T, cancel func()) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	backlog = append([]T(nil), rb.buf...)
+	c := make(chan T, subscriberBuffer)
+	rb.subs[c] = struct{}{}
+
+	cancel = func() {
+		rb.mu.Lock()
+		defer rb.mu.Unlock()
+		if _, ok := rb.subs[c]; ok {
+			delete(rb.subs, c)
+			close(c)
+		}
+	}
+
+	return backlog, c, cancel
+}
@@ -0,0 +1,32 @@
+package progressstore
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is an in-process Store, used by the standalone binary where
+// there's no DBOS Postgres pool to share. Snapshots reset on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	snapshots map[string]Snapshot
+}
+
+// NewMemoryStore creates a new in-memory progress store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string]Snapshot)}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, runID string, snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[runID] = snapshot
+	return nil
+}
+
+func (s *MemoryStore) Load(ctx context.Context, runID string) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[runID]
+	return snapshot, ok, nil
+}
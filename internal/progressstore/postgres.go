@@ -0,0 +1,74 @@
+package progressstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PostgresStore is a Store backed by a workflow_progress table, so the
+// latest progress snapshot for a run survives a worker restart. It's the
+// implementation used by the DBOS-backed worker, sharing the DBOS system
+// database pool.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new Postgres-backed progress store, creating
+// the workflow_progress table if it doesn't already exist.
+func NewPostgresStore(db *sql.DB) (*PostgresStore, error) {
+	store := &PostgresStore{db: db}
+	if err := store.ensureSchema(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure workflow_progress schema: %w", err)
+	}
+	return store, nil
+}
+
+func (s *PostgresStore) ensureSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS workflow_progress (
+			run_id TEXT PRIMARY KEY,
+			current_step TEXT,
+			step_index INTEGER NOT NULL DEFAULT 0,
+			step_count INTEGER NOT NULL DEFAULT 0,
+			message TEXT,
+			percent_complete DOUBLE PRECISION NOT NULL DEFAULT 0,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow_progress table: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Save(ctx context.Context, runID string, snapshot Snapshot) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO workflow_progress (run_id, current_step, step_index, step_count, message, percent_complete, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (run_id) DO UPDATE
+		SET current_step = $2, step_index = $3, step_count = $4, message = $5, percent_complete = $6, updated_at = NOW()
+	`, runID, snapshot.CurrentStep, snapshot.StepIndex, snapshot.StepCount, snapshot.Message, snapshot.PercentComplete)
+	if err != nil {
+		return fmt.Errorf("failed to save progress snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Load(ctx context.Context, runID string) (Snapshot, bool, error) {
+	var snapshot Snapshot
+	var currentStep, message sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT current_step, step_index, step_count, message, percent_complete
+		FROM workflow_progress WHERE run_id = $1
+	`, runID).Scan(&currentStep, &snapshot.StepIndex, &snapshot.StepCount, &message, &snapshot.PercentComplete)
+	if err == sql.ErrNoRows {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to load progress snapshot: %w", err)
+	}
+	snapshot.CurrentStep = currentStep.String
+	snapshot.Message = message.String
+	return snapshot, true, nil
+}
@@ -0,0 +1,33 @@
+// Package progressstore persists the latest step-based progress snapshot
+// for a workflow run, keyed by run ID, so WorkflowRunner.GetStatus can
+// surface it (as WorkflowStatus.CurrentStep/StepIndex/StepCount/Message/
+// PercentComplete) even after the process that ran the workflow restarts.
+// It's the same shape as internal/dedupe: a small interface with an
+// in-process MemoryStore and a Postgres-backed Store sharing the DBOS
+// system database pool.
+package progressstore
+
+import "context"
+
+// Snapshot is the latest progress report for one workflow run.
+type Snapshot struct {
+	CurrentStep     string
+	StepIndex       int
+	StepCount       int
+	Message         string
+	PercentComplete float64
+}
+
+// Store persists and retrieves the latest Snapshot for a run ID.
+// MemoryStore backs it with an in-process map for standalone mode;
+// PostgresStore backs it with the DBOS Postgres pool so snapshots survive
+// restarts.
+type Store interface {
+	// Save records snapshot as the latest progress for runID, replacing
+	// whatever was previously saved.
+	Save(ctx context.Context, runID string, snapshot Snapshot) error
+
+	// Load returns the latest saved Snapshot for runID. ok is false if
+	// nothing has been saved for runID yet.
+	Load(ctx context.Context, runID string) (snapshot Snapshot, ok bool, err error)
+}
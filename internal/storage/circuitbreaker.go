@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a request is rejected because the
+// circuit breaker for a host has tripped.
+var ErrCircuitOpen = errors.New("circuit breaker open: backend appears to be down")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker trips after a configurable number of consecutive failures,
+// so a dead simple-content backend fails fast instead of stalling every
+// worker on a retry loop. One breaker is scoped to a single client (and
+// therefore a single host).
+type circuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	resetTimeout time.Duration
+	failures     int
+	state        circuitState
+	openedAt     time.Time
+}
+
+func newCircuitBreaker(threshold int, resetTimeout time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if resetTimeout <= 0 {
+		resetTimeout = 30 * time.Second
+	}
+	return &circuitBreaker{threshold: threshold, resetTimeout: resetTimeout}
+}
+
+// Allow reports whether a request may proceed, transitioning an open
+// breaker to half-open once resetTimeout has elapsed.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != circuitOpen {
+		return true
+	}
+	if time.Since(cb.openedAt) >= cb.resetTimeout {
+		cb.state = circuitHalfOpen
+		return true
+	}
+	return false
+}
+
+// RecordSuccess closes the breaker and resets the failure count.
+func (cb *circuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.state = circuitClosed
+}
+
+// RecordFailure increments the failure count, tripping the breaker once the
+// threshold is reached (or immediately if a half-open probe failed).
+func (cb *circuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+	}
+}
@@ -4,47 +4,116 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/google/uuid"
+	"github.com/tendant/simple-content-pipeline/pkg/retry"
 	"github.com/tendant/simple-content/pkg/simplecontent"
 )
 
 // DerivedWriter provides write access for derived content via simple-content service
 type DerivedWriter struct {
-	service simplecontent.Service
+	service     simplecontent.Service
+	retryPolicy retry.Policy
+
+	// digests is a process-local digest -> derived content ID index backing
+	// StatByDigest/RegisterDigest. The vendored simplecontent.Service has no
+	// tag or arbitrary-digest query in ListDerivedContentParams, so there is
+	// no way to ask the backing store itself for "has anyone ever produced
+	// this digest". Like DerivedGuard, this index is intra-process only: it
+	// dedupes concurrent/sequential work within one running instance, and
+	// is empty again after a restart.
+	digestsMu sync.RWMutex
+	digests   map[string]string
 }
 
 // NewDerivedWriter creates a new derived content writer
 func NewDerivedWriter(service simplecontent.Service) *DerivedWriter {
 	return &DerivedWriter{
-		service: service,
+		service:     service,
+		retryPolicy: retry.PolicyFromEnv(),
+		digests:     make(map[string]string),
 	}
 }
 
-// HasDerived checks if a derived output already exists for the given type/version
-func (dw *DerivedWriter) HasDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int) (bool, error) {
-	// Parse content ID
+// StatByDigest reports whether digest has already been registered (see
+// RegisterDigest), returning the derived content ID it maps to.
+func (dw *DerivedWriter) StatByDigest(ctx context.Context, digest string) (bool, string, error) {
+	dw.digestsMu.RLock()
+	defer dw.digestsMu.RUnlock()
+	id, ok := dw.digests[digest]
+	return ok, id, nil
+}
+
+// RegisterDigest records that derivedContentID was produced for digest.
+func (dw *DerivedWriter) RegisterDigest(ctx context.Context, digest string, derivedContentID string) error {
+	dw.digestsMu.Lock()
+	defer dw.digestsMu.Unlock()
+	if dw.digests == nil {
+		dw.digests = make(map[string]string)
+	}
+	dw.digests[digest] = derivedContentID
+	return nil
+}
+
+// variantVersion parses the "<type>_v<version>" convention PutDerived
+// writes to its Variant field back into a numeric version, returning
+// false if variant isn't of that form for derivedType.
+func variantVersion(variant string, derivedType string) (int, bool) {
+	prefix := derivedType + "_v"
+	if !strings.HasPrefix(variant, prefix) {
+		return 0, false
+	}
+	version, err := strconv.Atoi(variant[len(prefix):])
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// listDerived lists derived content for contentID and derivedType,
+// retrying on transient backend errors.
+func (dw *DerivedWriter) listDerived(ctx context.Context, contentID string, derivedType string) ([]*simplecontent.DerivedContent, error) {
 	parentID, err := uuid.Parse(contentID)
 	if err != nil {
-		return false, fmt.Errorf("invalid content ID: %w", err)
+		return nil, fmt.Errorf("invalid content ID: %w", err)
 	}
 
-	// List derived content for parent
-	derived, err := dw.service.ListDerivedContent(ctx,
-		simplecontent.WithParentID(parentID),
-		simplecontent.WithDerivationType(derivedType),
-	)
+	var derived []*simplecontent.DerivedContent
+	err = retry.Do(ctx, dw.retryPolicy, "derived_writer.list_derived", func(ctx context.Context) error {
+		list, listErr := dw.service.ListDerivedContent(ctx,
+			simplecontent.WithParentID(parentID),
+			simplecontent.WithDerivationType(derivedType),
+		)
+		if listErr != nil {
+			return listErr
+		}
+		derived = list
+		return nil
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to list derived content: %w", err)
+		return nil, fmt.Errorf("failed to list derived content: %w", err)
+	}
+	return derived, nil
+}
+
+// HasDerived checks if a derived output already exists for the exact given
+// type and version, parsing each candidate's Variant back into
+// (type, version) via variantVersion so a bump in derivedVersion is never
+// silently mistaken for an existing, stale derived output.
+func (dw *DerivedWriter) HasDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int) (bool, error) {
+	derived, err := dw.listDerived(ctx, contentID, derivedType)
+	if err != nil {
+		return false, err
 	}
 
-	// Check if any derived content matches the version
-	// Note: simple-content stores variant, not version directly
-	// We'll need to check the variant string (e.g., "thumbnail_v1")
 	for _, d := range derived {
-		// For now, just check if derived type exists
-		// TODO: Properly handle version checking via variant
-		if d.DerivationType == derivedType {
+		if d.DerivationType != derivedType {
+			continue
+		}
+		if version, ok := variantVersion(d.Variant, derivedType); ok && version == derivedVersion {
 			return true, nil
 		}
 	}
@@ -52,7 +121,52 @@ func (dw *DerivedWriter) HasDerived(ctx context.Context, contentID string, deriv
 	return false, nil
 }
 
-// PutDerived creates or upserts a derived output and returns its derived content ID
+// ListDerivedVersions returns the numeric versions of every derived output
+// of derivedType for contentID, so a workflow can decide whether to
+// regenerate (e.g. "regenerate if max(versions) < N").
+func (dw *DerivedWriter) ListDerivedVersions(ctx context.Context, contentID string, derivedType string) ([]int, error) {
+	derived, err := dw.listDerived(ctx, contentID, derivedType)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	for _, d := range derived {
+		if d.DerivationType != derivedType {
+			continue
+		}
+		if version, ok := variantVersion(d.Variant, derivedType); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+// GetDerivedByVersion returns the derived content ID for the given type
+// and exact version, or an error if no such derived output exists.
+func (dw *DerivedWriter) GetDerivedByVersion(ctx context.Context, contentID string, derivedType string, version int) (string, error) {
+	derived, err := dw.listDerived(ctx, contentID, derivedType)
+	if err != nil {
+		return "", err
+	}
+
+	for _, d := range derived {
+		if d.DerivationType != derivedType {
+			continue
+		}
+		if v, ok := variantVersion(d.Variant, derivedType); ok && v == version {
+			return d.ContentID.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no derived content of type %q version %d found for content %s", derivedType, version, contentID)
+}
+
+// PutDerived creates or upserts a derived output and returns its derived
+// content ID. r is made replayable via seekableReader so a transient
+// backend failure can be retried without the caller having to supply an
+// io.ReadSeeker itself.
 func (dw *DerivedWriter) PutDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int, r io.Reader, meta map[string]string) (string, error) {
 	// Parse parent content ID
 	parentID, err := uuid.Parse(contentID)
@@ -69,14 +183,30 @@ func (dw *DerivedWriter) PutDerived(ctx context.Context, contentID string, deriv
 		fileName = fmt.Sprintf("derived_%s.dat", derivedType)
 	}
 
-	// Upload derived content using simple-content
-	derivedContent, err := dw.service.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
-		ParentID:       parentID,
-		DerivationType: derivedType,
-		Variant:        variant,
-		Reader:         r,
-		FileName:       fileName,
-		Tags:           []string{derivedType, variant},
+	body, cleanup, err := seekableReader(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare derived content for upload: %w", err)
+	}
+	defer cleanup()
+
+	var derivedContent *simplecontent.Content
+	err = retry.Do(ctx, dw.retryPolicy, "derived_writer.put_derived", func(ctx context.Context) error {
+		if _, seekErr := body.Seek(0, io.SeekStart); seekErr != nil {
+			return seekErr
+		}
+		uploaded, uploadErr := dw.service.UploadDerivedContent(ctx, simplecontent.UploadDerivedContentRequest{
+			ParentID:       parentID,
+			DerivationType: derivedType,
+			Variant:        variant,
+			Reader:         body,
+			FileName:       fileName,
+			Tags:           []string{derivedType, variant},
+		})
+		if uploadErr != nil {
+			return uploadErr
+		}
+		derivedContent = uploaded
+		return nil
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload derived content: %w", err)
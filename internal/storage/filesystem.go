@@ -2,39 +2,79 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/tendant/simple-content-pipeline/pkg/retry"
 )
 
+// FSOption configures a FilesystemStorage.
+type FSOption func(*FilesystemStorage)
+
+// WithFSRetryPolicy overrides the retry policy used for transient
+// filesystem errors (e.g. EAGAIN under load). Defaults to
+// retry.PolicyFromEnv().
+func WithFSRetryPolicy(policy retry.Policy) FSOption {
+	return func(fs *FilesystemStorage) { fs.retryPolicy = policy }
+}
+
 // FilesystemStorage implements storage.Reader for local filesystem
 type FilesystemStorage struct {
-	baseDir string
+	baseDir     string
+	retryPolicy retry.Policy
 }
 
 // NewFilesystemStorage creates a new filesystem storage reader
-func NewFilesystemStorage(baseDir string) (*FilesystemStorage, error) {
+func NewFilesystemStorage(baseDir string, opts ...FSOption) (*FilesystemStorage, error) {
 	// Ensure base directory exists
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
 	}
 
-	return &FilesystemStorage{
-		baseDir: baseDir,
-	}, nil
+	fs := &FilesystemStorage{
+		baseDir:     baseDir,
+		retryPolicy: retry.PolicyFromEnv(),
+	}
+	for _, opt := range opts {
+		opt(fs)
+	}
+
+	return fs, nil
 }
 
-// GetReader returns a reader for the file at the given key
-func (fs *FilesystemStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+// resolvePath joins key onto baseDir, rejecting any key that would escape it
+// via "..".
+func (fs *FilesystemStorage) resolvePath(key string) (string, error) {
 	path := filepath.Join(fs.baseDir, key)
+	rel, err := filepath.Rel(filepath.Clean(fs.baseDir), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key: path traversal detected")
+	}
+	return path, nil
+}
 
-	// Security: prevent directory traversal
-	if !filepath.HasPrefix(filepath.Clean(path), filepath.Clean(fs.baseDir)) {
-		return nil, fmt.Errorf("invalid key: path traversal detected")
+// GetReader returns a reader for the file at the given key
+func (fs *FilesystemStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := fs.resolvePath(key)
+	if err != nil {
+		return nil, err
 	}
 
-	file, err := os.Open(path)
+	var file *os.File
+	err = retry.Do(ctx, fs.retryPolicy, "filesystem.get_reader", func(ctx context.Context) error {
+		f, openErr := os.Open(path)
+		if openErr != nil {
+			return openErr
+		}
+		file = f
+		return nil
+	})
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("file not found: %s", key)
@@ -47,14 +87,15 @@ func (fs *FilesystemStorage) GetReader(ctx context.Context, key string) (io.Read
 
 // Exists checks if a file exists at the given key
 func (fs *FilesystemStorage) Exists(ctx context.Context, key string) (bool, error) {
-	path := filepath.Join(fs.baseDir, key)
-
-	// Security: prevent directory traversal
-	if !filepath.HasPrefix(filepath.Clean(path), filepath.Clean(fs.baseDir)) {
-		return false, fmt.Errorf("invalid key: path traversal detected")
+	path, err := fs.resolvePath(key)
+	if err != nil {
+		return false, err
 	}
 
-	_, err := os.Stat(path)
+	err = retry.Do(ctx, fs.retryPolicy, "filesystem.exists", func(ctx context.Context) error {
+		_, statErr := os.Stat(path)
+		return statErr
+	})
 	if err != nil {
 		if os.IsNotExist(err) {
 			return false, nil
@@ -65,16 +106,24 @@ func (fs *FilesystemStorage) Exists(ctx context.Context, key string) (bool, erro
 	return true, nil
 }
 
-// GetMetadata returns metadata for the file at the given key
+// GetMetadata returns metadata for the file at the given key: Size always,
+// ContentType sniffed from the first 512 bytes, and ETag from a cached
+// SHA-256 of the file contents (see etagFor).
 func (fs *FilesystemStorage) GetMetadata(ctx context.Context, key string) (*Metadata, error) {
-	path := filepath.Join(fs.baseDir, key)
-
-	// Security: prevent directory traversal
-	if !filepath.HasPrefix(filepath.Clean(path), filepath.Clean(fs.baseDir)) {
-		return nil, fmt.Errorf("invalid key: path traversal detected")
+	path, err := fs.resolvePath(key)
+	if err != nil {
+		return nil, err
 	}
 
-	info, err := os.Stat(path)
+	var info os.FileInfo
+	err = retry.Do(ctx, fs.retryPolicy, "filesystem.get_metadata", func(ctx context.Context) error {
+		i, statErr := os.Stat(path)
+		if statErr != nil {
+			return statErr
+		}
+		info = i
+		return nil
+	})
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("file not found: %s", key)
@@ -82,9 +131,114 @@ func (fs *FilesystemStorage) GetMetadata(ctx context.Context, key string) (*Meta
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
+	contentType, err := detectContentType(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+
+	etag, err := fs.etagFor(path, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute etag: %w", err)
+	}
+
 	return &Metadata{
-		Size: info.Size(),
-		// ContentType detection would require reading file headers
-		// Can be added later if needed
+		Size:        info.Size(),
+		ContentType: contentType,
+		ETag:        etag,
 	}, nil
 }
+
+// detectContentType sniffs path's content type from its first 512 bytes,
+// the same amount http.DetectContentType inspects.
+func detectContentType(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// etagSidecarSuffix marks the sidecar file next to an object that caches its
+// SHA-256 ETag, keyed by the mtime and size it was computed from.
+const etagSidecarSuffix = ".etag"
+
+// etagFor returns a SHA-256-based ETag for the file at path, reusing the
+// sidecar cache at path+etagSidecarSuffix when its recorded mtime and size
+// still match info, and recomputing (then rewriting the sidecar) otherwise.
+// This avoids rehashing a large, unchanged object on every GetMetadata call.
+func (fs *FilesystemStorage) etagFor(path string, info os.FileInfo) (string, error) {
+	sidecarPath := path + etagSidecarSuffix
+	stamp := fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+
+	if cached, err := os.ReadFile(sidecarPath); err == nil {
+		if parts := strings.SplitN(strings.TrimSpace(string(cached)), " ", 2); len(parts) == 2 && parts[0] == stamp {
+			return parts[1], nil
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	etag := hex.EncodeToString(h.Sum(nil))
+
+	// Best-effort cache write; a failure here just means the next
+	// GetMetadata call recomputes the hash.
+	_ = os.WriteFile(sidecarPath, []byte(stamp+" "+etag), 0644)
+
+	return etag, nil
+}
+
+// GetRangeReader returns a reader for length bytes starting at offset in the
+// file at key. A length of 0 or less reads to the end of the file.
+func (fs *FilesystemStorage) GetRangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	path, err := fs.resolvePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var file *os.File
+	err = retry.Do(ctx, fs.retryPolicy, "filesystem.get_range_reader", func(ctx context.Context) error {
+		f, openErr := os.Open(path)
+		if openErr != nil {
+			return openErr
+		}
+		if _, seekErr := f.Seek(offset, io.SeekStart); seekErr != nil {
+			f.Close()
+			return seekErr
+		}
+		file = f
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file not found: %s", key)
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if length <= 0 {
+		return file, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// limitedReadCloser pairs an io.LimitReader over an open file with that
+// file's Close, so GetRangeReader can return a single io.ReadCloser.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
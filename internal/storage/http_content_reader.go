@@ -5,44 +5,132 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPContentReader provides read access to content via simple-content HTTP API
 type HTTPContentReader struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	breaker     *circuitBreaker
 }
 
-// NewHTTPContentReader creates a new HTTP-based content reader
-func NewHTTPContentReader(baseURL string) *HTTPContentReader {
+// NewHTTPContentReader creates a new HTTP-based content reader. By default
+// idempotent requests are retried with DefaultRetryPolicy and guarded by a
+// per-host circuit breaker; use WithRetryPolicy, WithCircuitBreaker, or
+// WithHTTPClient to override.
+func NewHTTPContentReader(baseURL string, opts ...HTTPOption) *HTTPContentReader {
+	cfg := defaultHTTPClientConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return &HTTPContentReader{
-		baseURL:    baseURL,
-		httpClient: &http.Client{},
+		baseURL:     baseURL,
+		httpClient:  instrumentedClient(cfg.httpClient),
+		retryPolicy: cfg.retryPolicy,
+		breaker:     newCircuitBreaker(cfg.breakerThreshold, cfg.breakerReset),
 	}
 }
 
 // GetReaderByContentID returns a reader for content by content ID via HTTP API
 func (cr *HTTPContentReader) GetReaderByContentID(ctx context.Context, contentID string) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/api/v1/contents/%s/download", cr.baseURL, contentID)
+	ctx, span := tracer.Start(ctx, "storage.HTTPContentReader.GetReaderByContentID",
+		trace.WithAttributes(attribute.String("content.id", contentID)))
+	defer span.End()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	url := fmt.Sprintf("%s/api/v1/contents/%s/download", cr.baseURL, contentID)
 
-	resp, err := cr.httpClient.Do(req)
+	resp, err := cr.getWithRetry(ctx, url, nil)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to download content: %w", err)
 	}
+	span.SetAttributes(attribute.Int("http.status", resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+		err := fmt.Errorf("download failed with status %d", resp.StatusCode)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	return resp.Body, nil
 }
 
+// getWithRetry performs a GET request, retrying on transient failures
+// (network errors, 429/5xx, honoring Retry-After) up to retryPolicy's
+// MaxAttempts, and failing fast via the circuit breaker if the host has
+// been tripped by consecutive failures. GET is idempotent, so it is always
+// safe to retry. header is merged into each attempt's request (e.g. a Range
+// header); it may be nil. The caller is responsible for closing the
+// returned response body.
+func (cr *HTTPContentReader) getWithRetry(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	if !cr.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	maxAttempts := cr.retryPolicy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		for k, values := range header {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+
+		resp, err := cr.httpClient.Do(req)
+		retryWait := cr.retryPolicy.Backoff(attempt)
+
+		switch {
+		case err != nil:
+			cr.breaker.RecordFailure()
+			lastErr = err
+		case retryableStatus(resp.StatusCode):
+			cr.breaker.RecordFailure()
+			lastErr = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			if d, ok := parseRetryAfter(resp.Header); ok {
+				retryWait = d
+			}
+			resp.Body.Close()
+		default:
+			cr.breaker.RecordSuccess()
+			retry.RecordAttempt(ctx, "content_reader.get", retry.AttemptSuccess)
+			return resp, nil
+		}
+
+		last := attempt == maxAttempts-1 || exceedsElapsed(cr.retryPolicy, start)
+		if last {
+			retry.RecordAttempt(ctx, "content_reader.get", retry.AttemptTerminal)
+			break
+		}
+		retry.RecordAttempt(ctx, "content_reader.get", retry.AttemptRetry)
+		if !sleepOrDone(ctx, retryWait) {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
 // GetReader returns a reader for content (implements storage.Reader interface)
 func (cr *HTTPContentReader) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
 	return cr.GetReaderByContentID(ctx, key)
@@ -50,18 +138,20 @@ func (cr *HTTPContentReader) GetReader(ctx context.Context, key string) (io.Read
 
 // Exists checks if content exists by content ID via HTTP API
 func (cr *HTTPContentReader) Exists(ctx context.Context, key string) (bool, error) {
-	url := fmt.Sprintf("%s/api/v1/contents/%s", cr.baseURL, key)
+	ctx, span := tracer.Start(ctx, "storage.HTTPContentReader.Exists",
+		trace.WithAttributes(attribute.String("content.id", key)))
+	defer span.End()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
-	}
+	url := fmt.Sprintf("%s/api/v1/contents/%s", cr.baseURL, key)
 
-	resp, err := cr.httpClient.Do(req)
+	resp, err := cr.getWithRetry(ctx, url, nil)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return false, fmt.Errorf("failed to check content: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.status", resp.StatusCode))
 
 	if resp.StatusCode == http.StatusOK {
 		return true, nil
@@ -70,7 +160,10 @@ func (cr *HTTPContentReader) Exists(ctx context.Context, key string) (bool, erro
 		return false, nil
 	}
 
-	return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return false, err
 }
 
 // GetMetadata returns metadata for content via HTTP API
@@ -78,3 +171,44 @@ func (cr *HTTPContentReader) GetMetadata(ctx context.Context, key string) (*Meta
 	// TODO: Implement using /api/v1/contents/{id}/details endpoint
 	return &Metadata{}, nil
 }
+
+// GetRangeReader returns a reader for length bytes starting at offset in the
+// content at contentID, via an RFC 7233 Range request. A length of 0 or less
+// requests to the end of the content (an open-ended "bytes=start-" range).
+// The server must respond 206 Partial Content; any other status (including
+// a 200 that silently ignored the Range header) is an error.
+func (cr *HTTPContentReader) GetRangeReader(ctx context.Context, contentID string, offset, length int64) (io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "storage.HTTPContentReader.GetRangeReader",
+		trace.WithAttributes(
+			attribute.String("content.id", contentID),
+			attribute.Int64("range.offset", offset),
+			attribute.Int64("range.length", length),
+		))
+	defer span.End()
+
+	url := fmt.Sprintf("%s/api/v1/contents/%s/download", cr.baseURL, contentID)
+
+	rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		rangeHeader = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	header := http.Header{"Range": []string{rangeHeader}}
+
+	resp, err := cr.getWithRetry(ctx, url, header)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to download content range: %w", err)
+	}
+	span.SetAttributes(attribute.Int("http.status", resp.StatusCode))
+
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		err := fmt.Errorf("range request failed: expected status 206, got %d", resp.StatusCode)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
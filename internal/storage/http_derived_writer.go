@@ -8,39 +8,128 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// defaultChunkSize is used by PutDerivedChunked when ChunkSize is unset
+const defaultChunkSize = 5 << 20 // 5MB
+
+// defaultMaxRetries is used by PutDerivedChunked when MaxRetries is unset
+const defaultMaxRetries = 3
+
+// defaultProgressInterval throttles ProgressFunc invocations while uploading
+const defaultProgressInterval = 250 * time.Millisecond
+
+// ProgressFunc is invoked as derived content is uploaded, reporting bytes
+// sent so far and the total size if known (0 if the total is not known).
+type ProgressFunc func(bytesSent, totalBytes int64)
+
 // HTTPDerivedWriter provides write access for derived content via simple-content HTTP API
 type HTTPDerivedWriter struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	breaker     *circuitBreaker
+
+	// ChunkSize is the size of each chunk used by PutDerivedChunked.
+	// Defaults to 5MB if unset.
+	ChunkSize int64
+
+	// MaxRetries is the number of times a failed chunk is retried before
+	// PutDerivedChunked gives up. Defaults to 3 if unset.
+	MaxRetries int
+
+	// ProgressFunc, if set, is called periodically as PutDerived and
+	// PutDerivedChunked stream bytes to the server.
+	ProgressFunc ProgressFunc
+
+	// ProgressInterval throttles how often ProgressFunc is invoked.
+	// Defaults to 250ms if unset.
+	ProgressInterval time.Duration
+
+	// digests is a process-local digest -> derived content ID index backing
+	// StatByDigest/RegisterDigest. The simple-content HTTP API has no
+	// endpoint to query derived content by an arbitrary digest across
+	// parents, so this index only dedupes within this running instance (see
+	// the equivalent note on DerivedWriter.digests).
+	digestsMu sync.RWMutex
+	digests   map[string]string
 }
 
-// NewHTTPDerivedWriter creates a new HTTP-based derived content writer
-func NewHTTPDerivedWriter(baseURL string) *HTTPDerivedWriter {
+// NewHTTPDerivedWriter creates a new HTTP-based derived content writer. By
+// default HasDerived and PutDerived are retried with DefaultRetryPolicy and
+// guarded by a per-host circuit breaker; use WithRetryPolicy,
+// WithCircuitBreaker, or WithHTTPClient to override.
+//
+// PutDerived makes its body replayable (see seekableReader) and re-sends
+// the whole body on each retry. Use PutDerivedChunked for large uploads,
+// which retries only the chunk that failed.
+func NewHTTPDerivedWriter(baseURL string, opts ...HTTPOption) *HTTPDerivedWriter {
+	cfg := defaultHTTPClientConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return &HTTPDerivedWriter{
-		baseURL:    baseURL,
-		httpClient: &http.Client{},
+		baseURL:          baseURL,
+		httpClient:       instrumentedClient(cfg.httpClient),
+		retryPolicy:      cfg.retryPolicy,
+		breaker:          newCircuitBreaker(cfg.breakerThreshold, cfg.breakerReset),
+		ChunkSize:        defaultChunkSize,
+		MaxRetries:       defaultMaxRetries,
+		ProgressInterval: defaultProgressInterval,
+		digests:          make(map[string]string),
+	}
+}
+
+// StatByDigest reports whether digest has already been registered (see
+// RegisterDigest), returning the derived content ID it maps to.
+func (dw *HTTPDerivedWriter) StatByDigest(ctx context.Context, digest string) (bool, string, error) {
+	dw.digestsMu.RLock()
+	defer dw.digestsMu.RUnlock()
+	id, ok := dw.digests[digest]
+	return ok, id, nil
+}
+
+// RegisterDigest records that derivedContentID was produced for digest.
+func (dw *HTTPDerivedWriter) RegisterDigest(ctx context.Context, digest string, derivedContentID string) error {
+	dw.digestsMu.Lock()
+	defer dw.digestsMu.Unlock()
+	if dw.digests == nil {
+		dw.digests = make(map[string]string)
 	}
+	dw.digests[digest] = derivedContentID
+	return nil
 }
 
 // HasDerived checks if a derived output already exists for the given type/version
 func (dw *HTTPDerivedWriter) HasDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "storage.HTTPDerivedWriter.HasDerived", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+		attribute.String("derived.type", derivedType),
+		attribute.Int("derived.version", derivedVersion),
+	))
+	defer span.End()
+
 	// Construct the variant we're looking for
 	variant := fmt.Sprintf("%s_v%d", derivedType, derivedVersion)
 
 	// Query derived content from simple-content API
 	url := fmt.Sprintf("%s/api/v1/contents/%s/derived", dw.baseURL, contentID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := dw.httpClient.Do(req)
+	resp, err := dw.getWithRetry(ctx, url)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return false, fmt.Errorf("failed to query derived content: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.status", resp.StatusCode))
 
 	if resp.StatusCode == http.StatusNotFound {
 		// No derived content exists
@@ -49,12 +138,17 @@ func (dw *HTTPDerivedWriter) HasDerived(ctx context.Context, contentID string, d
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return false, fmt.Errorf("query derived failed with status %d: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("query derived failed with status %d: %s", resp.StatusCode, string(body))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
 	}
 
 	// Parse response
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return false, fmt.Errorf("failed to read response: %w", err)
 	}
 
@@ -63,6 +157,8 @@ func (dw *HTTPDerivedWriter) HasDerived(ctx context.Context, contentID string, d
 		Status  string `json:"status"`
 	}
 	if err := json.Unmarshal(bodyBytes, &derivedList); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return false, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -76,9 +172,20 @@ func (dw *HTTPDerivedWriter) HasDerived(ctx context.Context, contentID string, d
 	return false, nil
 }
 
-// PutDerived creates derived content via simple-content HTTP API using multipart upload
+// PutDerived creates derived content via simple-content HTTP API using a
+// streamed multipart upload. r is made replayable via seekableReader (an
+// io.ReadSeeker is used as-is; anything else is buffered, spilling to a
+// temp file above spillThreshold) so a transient failure can seek back to
+// the start and retry the whole request, rather than the previous
+// behavior of never retrying PutDerived at all.
 func (dw *HTTPDerivedWriter) PutDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int, r io.Reader, meta map[string]string) (string, error) {
-	// Create variant name from type and version
+	ctx, span := tracer.Start(ctx, "storage.HTTPDerivedWriter.PutDerived", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+		attribute.String("derived.type", derivedType),
+		attribute.Int("derived.version", derivedVersion),
+	))
+	defer span.End()
+
 	variant := fmt.Sprintf("%s_v%d", derivedType, derivedVersion)
 
 	fileName := meta["file_name"]
@@ -86,64 +193,377 @@ func (dw *HTTPDerivedWriter) PutDerived(ctx context.Context, contentID string, d
 		fileName = fmt.Sprintf("derived_%s.dat", derivedType)
 	}
 
-	// Read content into buffer
-	data, err := io.ReadAll(r)
+	var totalBytes int64
+	if sizeStr, ok := meta["size"]; ok {
+		fmt.Sscanf(sizeStr, "%d", &totalBytes)
+	}
+
+	body, cleanup, err := seekableReader(r)
 	if err != nil {
-		return "", fmt.Errorf("failed to read content: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	defer cleanup()
+
+	url := fmt.Sprintf("%s/api/v1/contents/%s/derived", dw.baseURL, contentID)
+
+	maxAttempts := dw.retryPolicy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	start := time.Now()
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if !dw.breaker.Allow() {
+			lastErr = ErrCircuitOpen
+			break
+		}
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return "", fmt.Errorf("failed to rewind derived content for retry: %w", err)
+		}
+
+		derivedID, status, putErr := dw.putDerivedOnce(ctx, url, derivedType, variant, fileName, body, totalBytes)
+		if putErr == nil {
+			retry.RecordAttempt(ctx, "derived_writer.put_derived", retry.AttemptSuccess)
+			return derivedID, nil
+		}
+		lastErr = putErr
+
+		last := attempt == maxAttempts-1 || exceedsElapsed(dw.retryPolicy, start) || retry.ClassifyHTTPStatus(status) == retry.Terminal
+		if last {
+			retry.RecordAttempt(ctx, "derived_writer.put_derived", retry.AttemptTerminal)
+			break
+		}
+		retry.RecordAttempt(ctx, "derived_writer.put_derived", retry.AttemptRetry)
+		if !sleepOrDone(ctx, dw.retryPolicy.Backoff(attempt)) {
+			lastErr = ctx.Err()
+			break
+		}
 	}
 
-	// Create multipart form with file and metadata
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+	return "", lastErr
+}
 
-	// Add derivation metadata
-	writer.WriteField("derivation_type", derivedType)
-	writer.WriteField("variant", variant)
+// putDerivedOnce performs a single multipart upload attempt of body
+// (already rewound by the caller). The returned status is 0 if the
+// request never got an HTTP response (e.g. a network error).
+func (dw *HTTPDerivedWriter) putDerivedOnce(ctx context.Context, url, derivedType, variant, fileName string, body io.ReadSeeker, totalBytes int64) (string, int, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
 
-	// Add file
-	part, err := writer.CreateFormFile("file", fileName)
+	go func() {
+		var err error
+		defer func() {
+			if err != nil {
+				pw.CloseWithError(err)
+			} else {
+				pw.Close()
+			}
+		}()
+
+		if err = writer.WriteField("derivation_type", derivedType); err != nil {
+			return
+		}
+		if err = writer.WriteField("variant", variant); err != nil {
+			return
+		}
+
+		var part io.Writer
+		part, err = writer.CreateFormFile("file", fileName)
+		if err != nil {
+			return
+		}
+
+		progress := &progressReader{
+			r:        body,
+			total:    totalBytes,
+			report:   dw.reportProgress,
+			interval: dw.progressInterval(),
+		}
+		if _, err = io.Copy(part, progress); err != nil {
+			return
+		}
+
+		err = writer.Close()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := dw.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %w", err)
+		dw.breaker.RecordFailure()
+		return "", 0, fmt.Errorf("failed to create derived content: %w", err)
 	}
-	if _, err := part.Write(data); err != nil {
-		return "", fmt.Errorf("failed to write file data: %w", err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		dw.breaker.RecordFailure()
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", resp.StatusCode, fmt.Errorf("create derived failed with status %d: %s", resp.StatusCode, string(respBody))
 	}
+	dw.breaker.RecordSuccess()
 
-	// Close the multipart writer
-	contentType := writer.FormDataContentType()
-	writer.Close()
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
 
-	// POST to derived content endpoint with multipart data
-	url := fmt.Sprintf("%s/api/v1/contents/%s/derived", dw.baseURL, contentID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	derivedID, err := parseDerivedID(bodyBytes)
+	if err != nil {
+		return "", resp.StatusCode, err
+	}
+	return derivedID, resp.StatusCode, nil
+}
+
+// getWithRetry performs a GET request, retrying on transient failures
+// (network errors, 429/5xx, honoring Retry-After) up to retryPolicy's
+// MaxAttempts, and failing fast via the circuit breaker if the host has
+// been tripped by consecutive failures. GET is idempotent, so it is always
+// safe to retry. The caller is responsible for closing the returned
+// response body.
+func (dw *HTTPDerivedWriter) getWithRetry(ctx context.Context, url string) (*http.Response, error) {
+	if !dw.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	maxAttempts := dw.retryPolicy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := dw.httpClient.Do(req)
+		retryWait := dw.retryPolicy.Backoff(attempt)
+
+		switch {
+		case err != nil:
+			dw.breaker.RecordFailure()
+			lastErr = err
+		case retryableStatus(resp.StatusCode):
+			dw.breaker.RecordFailure()
+			lastErr = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			if d, ok := parseRetryAfter(resp.Header); ok {
+				retryWait = d
+			}
+			resp.Body.Close()
+		default:
+			dw.breaker.RecordSuccess()
+			retry.RecordAttempt(ctx, "derived_writer.get", retry.AttemptSuccess)
+			return resp, nil
+		}
+
+		last := attempt == maxAttempts-1 || exceedsElapsed(dw.retryPolicy, start)
+		if last {
+			retry.RecordAttempt(ctx, "derived_writer.get", retry.AttemptTerminal)
+			break
+		}
+		retry.RecordAttempt(ctx, "derived_writer.get", retry.AttemptRetry)
+		if !sleepOrDone(ctx, retryWait) {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// PutDerivedChunked uploads derived content in Content-Range chunks,
+// negotiating a resumable session with the simple-content API and retrying
+// only the chunk that failed on transient errors. Use this instead of
+// PutDerived when r's total size is known up front and large enough that a
+// single request is likely to be interrupted.
+func (dw *HTTPDerivedWriter) PutDerivedChunked(ctx context.Context, contentID string, derivedType string, derivedVersion int, r io.Reader, totalSize int64, meta map[string]string) (string, error) {
+	variant := fmt.Sprintf("%s_v%d", derivedType, derivedVersion)
+
+	fileName := meta["file_name"]
+	if fileName == "" {
+		fileName = fmt.Sprintf("derived_%s.dat", derivedType)
+	}
+
+	sessionID, err := dw.negotiateChunkedSession(ctx, contentID, derivedType, variant, fileName, totalSize)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to negotiate chunked upload session: %w", err)
 	}
-	req.Header.Set("Content-Type", contentType)
+
+	chunkSize := dw.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	buf := make([]byte, chunkSize)
+	var sent int64
+	var derivedID string
+
+	for sent < totalSize {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+			return "", fmt.Errorf("failed to read chunk: %w", readErr)
+		}
+		if n == 0 {
+			break
+		}
+
+		start := sent
+		end := sent + int64(n) - 1
+
+		id, uploadErr := dw.uploadChunkWithRetry(ctx, contentID, sessionID, buf[:n], start, end, totalSize)
+		if uploadErr != nil {
+			return "", uploadErr
+		}
+
+		sent += int64(n)
+		dw.reportProgress(sent, totalSize)
+		if id != "" {
+			derivedID = id
+		}
+	}
+
+	if derivedID == "" {
+		return "", fmt.Errorf("chunked upload completed but no derived content ID was returned")
+	}
+
+	return derivedID, nil
+}
+
+// negotiateChunkedSession creates a resumable upload session and returns its session ID
+func (dw *HTTPDerivedWriter) negotiateChunkedSession(ctx context.Context, contentID, derivedType, variant, fileName string, totalSize int64) (string, error) {
+	if !dw.breaker.Allow() {
+		return "", ErrCircuitOpen
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"derivation_type": derivedType,
+		"variant":         variant,
+		"file_name":       fileName,
+		"total_size":      totalSize,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal session request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/contents/%s/derived/sessions", dw.baseURL, contentID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create session request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := dw.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to create derived content: %w", err)
+		return "", fmt.Errorf("failed to create session: %w", err)
 	}
 	defer resp.Body.Close()
 
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("create derived failed with status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("create session failed with status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	// Parse response to get derived content ID
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(bodyBytes, &session); err != nil {
+		return "", fmt.Errorf("failed to decode session response: %w", err)
+	}
+	if session.SessionID == "" {
+		return "", fmt.Errorf("no session_id in response")
+	}
+
+	return session.SessionID, nil
+}
+
+// uploadChunkWithRetry uploads a single chunk, retrying up to MaxRetries times on failure
+func (dw *HTTPDerivedWriter) uploadChunkWithRetry(ctx context.Context, contentID, sessionID string, chunk []byte, start, end, total int64) (string, error) {
+	maxRetries := dw.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(backoffDelay(attempt)):
+			}
+		}
+
+		id, err := dw.uploadChunk(ctx, contentID, sessionID, chunk, start, end, total)
+		if err == nil {
+			return id, nil
+		}
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("chunk %d-%d failed after %d attempts: %w", start, end, maxRetries+1, lastErr)
+}
+
+// uploadChunk uploads a single Content-Range chunk. The returned ID is only
+// populated once the server has received the final chunk.
+func (dw *HTTPDerivedWriter) uploadChunk(ctx context.Context, contentID, sessionID string, chunk []byte, start, end, total int64) (string, error) {
+	url := fmt.Sprintf("%s/api/v1/contents/%s/derived/sessions/%s", dw.baseURL, contentID, sessionID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(chunk))
+	if err != nil {
+		return "", fmt.Errorf("failed to create chunk request: %w", err)
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, total))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := dw.httpClient.Do(req)
+	if err != nil {
+		dw.breaker.RecordFailure()
+		return "", fmt.Errorf("chunk upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", fmt.Errorf("failed to read chunk response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		dw.breaker.RecordFailure()
+		return "", fmt.Errorf("chunk upload failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+	dw.breaker.RecordSuccess()
+
+	// Only the final chunk response carries the derived content ID
+	if end+1 < total {
+		return "", nil
 	}
 
+	return parseDerivedID(bodyBytes)
+}
+
+// parseDerivedID extracts the derived content ID from a simple-content JSON response
+func parseDerivedID(bodyBytes []byte) (string, error) {
 	var result map[string]interface{}
 	if err := json.Unmarshal(bodyBytes, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Extract ID from response
 	derivedID, ok := result["id"].(string)
 	if !ok {
 		// Try nested in "data" field
@@ -158,3 +578,47 @@ func (dw *HTTPDerivedWriter) PutDerived(ctx context.Context, contentID string, d
 
 	return derivedID, nil
 }
+
+// backoffDelay returns a small linear backoff between chunk retry attempts
+func backoffDelay(attempt int) time.Duration {
+	return time.Duration(attempt) * 500 * time.Millisecond
+}
+
+// reportProgress invokes ProgressFunc if set
+func (dw *HTTPDerivedWriter) reportProgress(sent, total int64) {
+	if dw.ProgressFunc != nil {
+		dw.ProgressFunc(sent, total)
+	}
+}
+
+func (dw *HTTPDerivedWriter) progressInterval() time.Duration {
+	if dw.ProgressInterval <= 0 {
+		return defaultProgressInterval
+	}
+	return dw.ProgressInterval
+}
+
+// progressReader wraps an io.Reader, invoking report at most once per
+// interval (plus a final call on EOF) with cumulative bytes read.
+type progressReader struct {
+	r          io.Reader
+	sent       int64
+	total      int64
+	report     func(sent, total int64)
+	interval   time.Duration
+	lastReport time.Time
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	if n > 0 {
+		p.sent += int64(n)
+	}
+	if p.report != nil {
+		if err == io.EOF || (n > 0 && time.Since(p.lastReport) >= p.interval) {
+			p.report(p.sent, p.total)
+			p.lastReport = time.Now()
+		}
+	}
+	return n, err
+}
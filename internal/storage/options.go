@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTPOption configures an HTTP-based storage client (HTTPContentReader or
+// HTTPDerivedWriter).
+type HTTPOption func(*httpClientConfig)
+
+type httpClientConfig struct {
+	httpClient       *http.Client
+	retryPolicy      RetryPolicy
+	breakerThreshold int
+	breakerReset     time.Duration
+}
+
+func defaultHTTPClientConfig() httpClientConfig {
+	return httpClientConfig{
+		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy(),
+	}
+}
+
+// WithRetryPolicy overrides the retry policy used for the client's retried
+// requests (GETs, and HTTPDerivedWriter.PutDerived).
+func WithRetryPolicy(policy RetryPolicy) HTTPOption {
+	return func(c *httpClientConfig) { c.retryPolicy = policy }
+}
+
+// WithCircuitBreaker sets the consecutive-failure threshold and reset
+// timeout for the per-host circuit breaker.
+func WithCircuitBreaker(threshold int, resetTimeout time.Duration) HTTPOption {
+	return func(c *httpClientConfig) {
+		c.breakerThreshold = threshold
+		c.breakerReset = resetTimeout
+	}
+}
+
+// WithHTTPClient overrides the underlying *http.Client.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(c *httpClientConfig) { c.httpClient = client }
+}
+
+// instrumentedClient returns a shallow copy of client with its transport
+// wrapped for OpenTelemetry tracing, leaving the caller's original client
+// (and transport) untouched.
+func instrumentedClient(client *http.Client) *http.Client {
+	instrumented := *client
+	base := instrumented.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	instrumented.Transport = otelhttp.NewTransport(base)
+	return &instrumented
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/retry"
+)
+
+// RetryPolicy configures retry behavior for HTTP storage clients. It is the
+// same type as retry.Policy so HTTP calls share the exponential-backoff
+// math, error classification, and pipeline_retry_attempts_total metric
+// used by FilesystemStorage and DerivedWriter.
+type RetryPolicy = retry.Policy
+
+// DefaultRetryPolicy returns a conservative retry policy suitable for most
+// simple-content HTTP API calls, overridable via STORAGE_RETRY_* env vars
+// (see retry.PolicyFromEnv).
+func DefaultRetryPolicy() RetryPolicy {
+	return retry.PolicyFromEnv()
+}
+
+// retryableStatus reports whether an HTTP status code is worth retrying:
+// 429 and 5xx are transient backend trouble; any other 4xx is a terminal
+// caller error.
+func retryableStatus(status int) bool {
+	return retry.ClassifyHTTPStatus(status) == retry.Retryable
+}
+
+// parseRetryAfter parses a Retry-After header (seconds or HTTP-date), if present.
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// exceedsElapsed reports whether MaxElapsedTime has passed since start.
+// A zero MaxElapsedTime means "no limit".
+func exceedsElapsed(p RetryPolicy, start time.Time) bool {
+	return p.ExceedsElapsed(start)
+}
+
+// sleepOrDone waits for d, returning false early if ctx is cancelled first.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// spillThreshold is the in-memory buffering limit before seekableReader
+// spills to a temp file; derived content larger than this (video
+// transcodes, ML embeddings) would otherwise double memory usage on every
+// retry attempt.
+const spillThreshold = 8 << 20 // 8MB
+
+// seekableReader ensures r can be replayed if a write needs to be retried:
+// an r that is already an io.ReadSeeker is returned as-is, a smaller r is
+// buffered in memory, and anything larger is spilled to a temp file. The
+// returned cleanup func removes any temp file created and must be called
+// once the caller is done with the reader.
+func seekableReader(r io.Reader) (io.ReadSeeker, func() error, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return rs, func() error { return nil }, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r, spillThreshold+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to buffer non-seekable reader for retry: %w", err)
+	}
+	if int64(len(buf)) <= spillThreshold {
+		return bytes.NewReader(buf), func() error { return nil }, nil
+	}
+
+	tmp, err := os.CreateTemp("", "derived-upload-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp spill file for retry: %w", err)
+	}
+	cleanup := func() error { return os.Remove(tmp.Name()) }
+
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to spill reader to temp file: %w", err)
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to spill reader to temp file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to rewind spill file: %w", err)
+	}
+
+	return tmp, func() error {
+		tmp.Close()
+		return os.Remove(tmp.Name())
+	}, nil
+}
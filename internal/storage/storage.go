@@ -28,3 +28,13 @@ type ReaderWithMetadata interface {
 	// GetMetadata returns metadata for content at the given key
 	GetMetadata(ctx context.Context, key string) (*Metadata, error)
 }
+
+// RangeReader provides partial reads of a stored object, so a caller that
+// only needs a prefix (image header sniffing, EXIF extraction, video probe)
+// doesn't have to stream the whole object first.
+type RangeReader interface {
+	// GetRangeReader returns a reader for length bytes starting at offset in
+	// the content at the given key. A length of 0 or less reads to the end
+	// of the object.
+	GetRangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
@@ -0,0 +1,8 @@
+package storage
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer = otel.Tracer("github.com/tendant/simple-content-pipeline/internal/storage")
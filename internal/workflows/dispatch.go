@@ -0,0 +1,246 @@
+package workflows
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// broadcastRetention is how long a run's statusBroadcast is kept around
+// after complete() fires. Subscribe's contract promises a late subscriber
+// (e.g. a dedup-coalesced caller that joined the run and calls Subscribe
+// shortly after it finished) the final status "immediately, if it already
+// finished" - deleting the entry the instant complete() runs would instead
+// hand that caller a freshly-recreated, never-completing broadcast. Keeping
+// it for a short grace period preserves that, while still bounding the
+// map's growth instead of leaking one entry per run for the worker's
+// lifetime.
+const broadcastRetention = 2 * time.Minute
+
+// dispatchKey derives a deterministic identifier from the parts of req that
+// determine its output: content ID, job, sorted versions, and a canonical
+// (sorted) form of its metadata. Two requests that would produce the same
+// derived output map to the same key, regardless of submission order or how
+// many times they're resubmitted.
+func dispatchKey(req pipeline.ProcessRequest) string {
+	h := sha256.New()
+	h.Write([]byte(req.ContentID))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Job))
+
+	versionKeys := make([]string, 0, len(req.Versions))
+	for k := range req.Versions {
+		versionKeys = append(versionKeys, k)
+	}
+	sort.Strings(versionKeys)
+	for _, k := range versionKeys {
+		fmt.Fprintf(h, "\x00v:%s=%d", k, req.Versions[k])
+	}
+
+	metaKeys := make([]string, 0, len(req.Metadata))
+	for k := range req.Metadata {
+		metaKeys = append(metaKeys, k)
+	}
+	sort.Strings(metaKeys)
+	for _, k := range metaKeys {
+		fmt.Fprintf(h, "\x00m:%s=%s", k, req.Metadata[k])
+	}
+
+	return fmt.Sprintf("%s-%x", req.Job, h.Sum(nil))
+}
+
+// statusBroadcast fans a single terminal WorkflowStatus out to every
+// subscriber waiting on it, so multiple callers for the same run ID can all
+// wait on one execution instead of each polling independently.
+type statusBroadcast struct {
+	mu      sync.Mutex
+	done    bool
+	status  WorkflowStatus
+	waiters []chan WorkflowStatus
+}
+
+func newStatusBroadcast() *statusBroadcast {
+	return &statusBroadcast{}
+}
+
+// subscribe returns a channel that receives status exactly once: either
+// immediately, if the run already finished, or when complete is next called.
+func (b *statusBroadcast) subscribe() (<-chan WorkflowStatus, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan WorkflowStatus, 1)
+	if b.done {
+		ch <- b.status
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.waiters = append(b.waiters, ch)
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, w := range b.waiters {
+			if w == ch {
+				b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, cancel
+}
+
+// complete delivers status to every current subscriber and to any future
+// Subscribe call. Only the first call has an effect.
+func (b *statusBroadcast) complete(status WorkflowStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+	b.status = status
+	for _, ch := range b.waiters {
+		ch <- status
+		close(ch)
+	}
+	b.waiters = nil
+}
+
+// dispatchRegistry tracks, per dispatch key, the run ID currently handling
+// it, plus a statusBroadcast per run ID for Subscribe. It's the in-memory
+// half of dedup: the DBOS workflow table (keyed by the same deterministic
+// ID dispatchKey produces) is the half that survives a restart.
+type dispatchRegistry struct {
+	mu        sync.Mutex
+	byKey     map[string]string // dispatch key -> live run ID
+	broadcast map[string]*statusBroadcast
+}
+
+func newDispatchRegistry() *dispatchRegistry {
+	return &dispatchRegistry{
+		byKey:     make(map[string]string),
+		broadcast: make(map[string]*statusBroadcast),
+	}
+}
+
+// claim registers runID as the live run for key, returning its broadcast.
+// Callers should already have confirmed no other run is live for key (or
+// accept overwriting a stale entry from a run that never completed).
+func (d *dispatchRegistry) claim(key, runID string) *statusBroadcast {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byKey[key] = runID
+	b := newStatusBroadcast()
+	d.broadcast[runID] = b
+	return b
+}
+
+// liveRunID returns the run ID currently claimed for key, if any.
+func (d *dispatchRegistry) liveRunID(key string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	runID, ok := d.byKey[key]
+	return runID, ok
+}
+
+// broadcastFor returns (creating if necessary) the statusBroadcast for
+// runID, so Subscribe works even for a run ID this registry never saw
+// dispatched in-process (e.g. one resumed via Retry, or queried after this
+// process restarted).
+func (d *dispatchRegistry) broadcastFor(runID string) *statusBroadcast {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.broadcast[runID]
+	if !ok {
+		b = newStatusBroadcast()
+		d.broadcast[runID] = b
+	}
+	return b
+}
+
+// complete marks key's run finished: the key is freed for a future dispatch
+// (e.g. a version bump) and every Subscribe(runID) caller is notified. The
+// runID's broadcast entry itself is dropped after broadcastRetention, not
+// immediately, so a late Subscribe(runID) call still sees the final status
+// - see broadcastRetention's comment.
+func (d *dispatchRegistry) complete(key, runID string, status WorkflowStatus) {
+	d.mu.Lock()
+	if d.byKey[key] == runID {
+		delete(d.byKey, key)
+	}
+	b, ok := d.broadcast[runID]
+	if !ok {
+		b = newStatusBroadcast()
+		d.broadcast[runID] = b
+	}
+	d.mu.Unlock()
+
+	b.complete(status)
+
+	// Only delete if the map still points at this exact broadcast: runID is
+	// deterministic for a deduped dispatch (workflowID = dispatchKey(req)),
+	// so a retry of the same request can claim a fresh broadcast under the
+	// same runID before this timer fires. Deleting unconditionally would
+	// drop that live, in-flight broadcast out from under the retry instead
+	// of the stale one this timer was scheduled for.
+	time.AfterFunc(broadcastRetention, func() {
+		d.mu.Lock()
+		if d.broadcast[runID] == b {
+			delete(d.broadcast, runID)
+		}
+		d.mu.Unlock()
+	})
+}
+
+// DerivedGuard coordinates concurrent attempts to produce the same derived
+// output, borrowing the idea from Docker's transfer manager: the first
+// caller to claim a key proceeds, and any other caller for the same key
+// waits for it to finish instead of starting a duplicate generate-and-upload.
+// A nil *DerivedGuard always grants ownership, so callers that build a
+// WorkflowContext without one (e.g. a not-yet-wired dispatch path) behave
+// exactly as before this type existed.
+type DerivedGuard struct {
+	mu     sync.Mutex
+	claims map[string]chan struct{}
+}
+
+// NewDerivedGuard creates an empty DerivedGuard.
+func NewDerivedGuard() *DerivedGuard {
+	return &DerivedGuard{claims: make(map[string]chan struct{})}
+}
+
+// Claim attempts to become the sole owner of key. If another caller already
+// owns key, Claim blocks until that caller's release func is called, then
+// returns owner=false so the loser can skip its own generate-and-upload
+// entirely (trusting the owner either produced the output or is producing
+// it). The owner must call release exactly once when its attempt finishes,
+// successfully or not, to free key for a later legitimate run.
+func (g *DerivedGuard) Claim(key string) (owner bool, release func()) {
+	if g == nil {
+		return true, func() {}
+	}
+
+	g.mu.Lock()
+	if done, ok := g.claims[key]; ok {
+		g.mu.Unlock()
+		<-done
+		return false, func() {}
+	}
+
+	done := make(chan struct{})
+	g.claims[key] = done
+	g.mu.Unlock()
+
+	return true, func() {
+		g.mu.Lock()
+		delete(g.claims, key)
+		g.mu.Unlock()
+		close(done)
+	}
+}
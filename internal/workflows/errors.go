@@ -11,4 +11,28 @@ var (
 
 	// ErrInvalidRequest is returned when the request is invalid
 	ErrInvalidRequest = errors.New("invalid workflow request")
+
+	// ErrCancelled is returned by a Workflow's Execute when it observes
+	// wctx.Ctx.Err() != nil between steps after Cancel has been called for
+	// its run. WorkflowRunner recognizes it and reports the run's GetStatus
+	// state as "cancelled" instead of "failed".
+	ErrCancelled = errors.New("workflow cancelled")
 )
+
+// permanentError wraps an error that retry.Do must not retry: a decode
+// failure, an unsupported format, or a validation error, as opposed to a
+// transient I/O error from a download or upload. It implements
+// retry.Permanent.
+type permanentError struct{ err error }
+
+// permanent wraps err so retry.IsPermanent reports true for it.
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func (p permanentError) Error() string   { return p.err.Error() }
+func (p permanentError) Unwrap() error   { return p.err }
+func (p permanentError) Permanent() bool { return true }
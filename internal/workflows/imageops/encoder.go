@@ -0,0 +1,105 @@
+package imageops
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sync"
+)
+
+// Encoder encodes img to w. quality is in [1, 100] and ignored by lossless
+// formats (e.g. PNG).
+type Encoder interface {
+	Encode(w io.Writer, img image.Image, quality int) error
+}
+
+// ErrUnknownFormat is returned by EncoderFor when mime has no registered
+// Encoder.
+var ErrUnknownFormat = errors.New("imageops: no encoder registered for format")
+
+type jpegEncoder struct{}
+
+func (jpegEncoder) Encode(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+type pngEncoder struct{}
+
+func (pngEncoder) Encode(w io.Writer, img image.Image, quality int) error {
+	return png.Encode(w, img)
+}
+
+var (
+	registryMu sync.RWMutex
+
+	// registry starts with the two formats the standard library already
+	// gives us for free. WebP and AVIF are NOT registered by default: this
+	// repo has no cgo-free WebP encoder vendored (github.com/chai2010/webp
+	// requires cgo; golang.org/x/image/webp only decodes) and no maintained
+	// Go AVIF encoder at all, and this environment has no network access to
+	// add one. RegisterEncoder is the intended extension point for a
+	// downstream build that vendors one of those.
+	registry = map[string]Encoder{
+		"image/jpeg": jpegEncoder{},
+		"image/png":  pngEncoder{},
+	}
+)
+
+// RegisterEncoder makes enc available for mime, overriding any previous
+// registration for it (including the builtin JPEG/PNG encoders). Safe to
+// call concurrently with EncoderFor.
+func RegisterEncoder(mime string, enc Encoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[mime] = enc
+}
+
+// EncoderFor returns the registered Encoder for mime, or ErrUnknownFormat
+// if none has been registered.
+func EncoderFor(mime string) (Encoder, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	enc, ok := registry[mime]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, mime)
+	}
+	return enc, nil
+}
+
+// MimeForFormat maps a rendition's short format name (as used in
+// pipeline.Rendition.Format) to the MIME type EncoderFor and PutDerived's
+// metadata expect.
+func MimeForFormat(format string) (string, error) {
+	switch format {
+	case "jpeg", "jpg":
+		return "image/jpeg", nil
+	case "png":
+		return "image/png", nil
+	case "webp":
+		return "image/webp", nil
+	case "avif":
+		return "image/avif", nil
+	default:
+		return "", fmt.Errorf("imageops: unknown format %q", format)
+	}
+}
+
+// ExtForMime returns the conventional file extension for a MIME type
+// registered through this package, for building a rendition's file_name.
+func ExtForMime(mime string) string {
+	switch mime {
+	case "image/jpeg":
+		return "jpg"
+	case "image/png":
+		return "png"
+	case "image/webp":
+		return "webp"
+	case "image/avif":
+		return "avif"
+	default:
+		return "dat"
+	}
+}
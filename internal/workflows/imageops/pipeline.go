@@ -0,0 +1,132 @@
+// Package imageops provides a small decode -> [Op...] -> encode pipeline
+// for generating image renditions, so a workflow can compose resize/crop/
+// orient steps and pick an output format per rendition instead of having
+// a single hardcoded transform baked into its Execute method.
+package imageops
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// Op transforms an image and returns the result. Implementations must not
+// assume they're the only Op in a pipeline, or that the caller won't reuse
+// the same source image across multiple independent pipelines (e.g. one
+// per rendition of a fan-out run).
+type Op interface {
+	Apply(img image.Image) (image.Image, error)
+}
+
+// Pipeline runs a source image through an ordered list of Ops.
+type Pipeline struct {
+	Ops []Op
+}
+
+// Run applies each Op in order, returning the final image.
+func (p Pipeline) Run(img image.Image) (image.Image, error) {
+	out := img
+	for _, op := range p.Ops {
+		next, err := op.Apply(out)
+		if err != nil {
+			return nil, err
+		}
+		out = next
+	}
+	return out, nil
+}
+
+// filterOrDefault substitutes imaging.Lanczos for the zero value of
+// imaging.ResampleFilter, so callers that don't care about the resampling
+// algorithm can leave Resize/Fit's Filter field unset.
+func filterOrDefault(f imaging.ResampleFilter) imaging.ResampleFilter {
+	if f.Kernel == nil {
+		return imaging.Lanczos
+	}
+	return f
+}
+
+// Resize scales img to exactly Width x Height, distorting its aspect ratio
+// if they don't match. Use Fit to preserve aspect ratio instead.
+type Resize struct {
+	Width, Height int
+	Filter        imaging.ResampleFilter
+}
+
+func (o Resize) Apply(img image.Image) (image.Image, error) {
+	return imaging.Resize(img, o.Width, o.Height, filterOrDefault(o.Filter)), nil
+}
+
+// Fit scales img down to fit within Width x Height, preserving aspect
+// ratio (the same behavior ThumbnailWorkflow used before this package
+// existed).
+type Fit struct {
+	Width, Height int
+	Filter        imaging.ResampleFilter
+}
+
+func (o Fit) Apply(img image.Image) (image.Image, error) {
+	return imaging.Fit(img, o.Width, o.Height, filterOrDefault(o.Filter)), nil
+}
+
+// Crop extracts a Width x Height region anchored at (X, Y).
+type Crop struct {
+	X, Y, Width, Height int
+}
+
+func (o Crop) Apply(img image.Image) (image.Image, error) {
+	b := img.Bounds()
+	rect := image.Rect(b.Min.X+o.X, b.Min.Y+o.Y, b.Min.X+o.X+o.Width, b.Min.Y+o.Y+o.Height)
+	return imaging.Crop(img, rect), nil
+}
+
+// SmartCrop crops img to Width x Height around its center. This is a
+// placeholder for real saliency-based cropping: this repo doesn't vendor a
+// subject-detection model to crop around, so "smart" here just means
+// "centered". It's still a drop-in Op, so a real implementation can
+// replace it later without any caller needing to change.
+type SmartCrop struct {
+	Width, Height int
+}
+
+func (o SmartCrop) Apply(img image.Image) (image.Image, error) {
+	return imaging.CropCenter(img, o.Width, o.Height), nil
+}
+
+// Orient applies the geometric transform for EXIF orientation tag Value
+// (1-8, per the TIFF/EXIF spec; 1 or 0 is a no-op). This repo doesn't
+// vendor an EXIF reader, so Value must come from wherever the caller reads
+// EXIF data - Orient only applies the resulting rotation/flip.
+type Orient struct {
+	Value int
+}
+
+func (o Orient) Apply(img image.Image) (image.Image, error) {
+	switch o.Value {
+	case 2:
+		return imaging.FlipH(img), nil
+	case 3:
+		return imaging.Rotate180(img), nil
+	case 4:
+		return imaging.FlipV(img), nil
+	case 5:
+		return imaging.Transpose(img), nil
+	case 6:
+		return imaging.Rotate270(img), nil
+	case 7:
+		return imaging.Transverse(img), nil
+	case 8:
+		return imaging.Rotate90(img), nil
+	default:
+		return img, nil
+	}
+}
+
+// Strip is a deliberate no-op: decoding a source file into an image.Image
+// already discards any EXIF/ICC/XMP metadata it carried, so every pipeline
+// in this package is implicitly metadata-stripped on encode. Strip lets a
+// caller still say "no metadata" explicitly in a rendition's op list
+// without special-casing its absence.
+type Strip struct{}
+
+func (Strip) Apply(img image.Image) (image.Image, error) { return img, nil }
@@ -0,0 +1,365 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline/retry"
+	"gopkg.in/yaml.v3"
+)
+
+// Step kinds understood by the manifest compiler.
+const (
+	StepDownload  = "download"
+	StepTransform = "transform"
+	StepUpload    = "upload"
+)
+
+// StepSpec is one step of a manifest-driven WorkflowSpec: what kind of step
+// it is, its kind-specific config, and an optional retry policy.
+type StepSpec struct {
+	Type   string                 `json:"type" yaml:"type"`
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Retry  *StepRetrySpec         `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// StepRetrySpec configures a fixed number of retry attempts with a constant
+// backoff between them, for steps that may fail transiently (e.g. a flaky
+// upload). A nil *StepRetrySpec means no retry: the step runs once.
+type StepRetrySpec struct {
+	MaxAttempts int           `json:"max_attempts" yaml:"max_attempts"`
+	Backoff     time.Duration `json:"backoff" yaml:"backoff"`
+}
+
+// WorkflowSpec is a declarative description of a workflow: the job type it
+// serves, the derived type it produces, and the ordered steps that produce
+// it. A registered WorkflowSpec compiles into the same Workflow interface
+// as a hand-written Go workflow like ThumbnailWorkflow.
+type WorkflowSpec struct {
+	Job         string     `json:"job" yaml:"job"`
+	DerivedType string     `json:"derived_type" yaml:"derived_type"`
+	Steps       []StepSpec `json:"steps" yaml:"steps"`
+}
+
+func (s WorkflowSpec) validate() error {
+	if s.Job == "" {
+		return fmt.Errorf("job is required")
+	}
+	if s.DerivedType == "" {
+		return fmt.Errorf("derived_type is required")
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("steps must not be empty")
+	}
+	for i, step := range s.Steps {
+		switch step.Type {
+		case StepDownload, StepTransform, StepUpload:
+		default:
+			return fmt.Errorf("step %d: unknown step type %q", i, step.Type)
+		}
+		if step.Retry != nil && step.Retry.MaxAttempts < 1 {
+			return fmt.Errorf("step %d: retry.max_attempts must be at least 1", i)
+		}
+	}
+	return nil
+}
+
+// ManifestRegistry compiles WorkflowSpecs into Workflow implementations
+// backed by a shared ContentReader/DerivedWriter, registers them with a
+// WorkflowRunner, and keeps track of the specs it was given so they can be
+// listed back over HTTP or reloaded.
+type ManifestRegistry struct {
+	contentReader ContentReader
+	derivedWriter DerivedWriter
+
+	mu    sync.RWMutex
+	specs map[string]WorkflowSpec // keyed by job
+}
+
+// NewManifestRegistry creates a manifest registry that compiles specs
+// against the given content reader and derived writer.
+func NewManifestRegistry(contentReader ContentReader, derivedWriter DerivedWriter) *ManifestRegistry {
+	return &ManifestRegistry{
+		contentReader: contentReader,
+		derivedWriter: derivedWriter,
+		specs:         make(map[string]WorkflowSpec),
+	}
+}
+
+// Load compiles spec and registers it with runner under spec.Job, replacing
+// any workflow (manifest-driven or not) previously registered for that job.
+func (m *ManifestRegistry) Load(runner *WorkflowRunner, spec WorkflowSpec) error {
+	if err := spec.validate(); err != nil {
+		return fmt.Errorf("invalid workflow spec: %w", err)
+	}
+
+	wf := &manifestWorkflow{
+		spec:          spec,
+		contentReader: m.contentReader,
+		derivedWriter: m.derivedWriter,
+	}
+
+	m.mu.Lock()
+	m.specs[spec.Job] = spec
+	m.mu.Unlock()
+
+	runner.Register(spec.Job, wf)
+	return nil
+}
+
+// LoadDir reads every *.yaml, *.yml, and *.json file in dir (non-recursive)
+// and loads each as a WorkflowSpec, for the --workflows-dir startup flag.
+func (m *ManifestRegistry) LoadDir(runner *WorkflowRunner, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read workflows dir %q: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read workflow manifest %q: %w", path, err)
+		}
+
+		spec, err := ParseWorkflowSpec(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse workflow manifest %q: %w", path, err)
+		}
+
+		if err := m.Load(runner, spec); err != nil {
+			return fmt.Errorf("failed to load workflow manifest %q: %w", path, err)
+		}
+
+		log.Printf("✓ Loaded workflow manifest: %s (job=%s)", path, spec.Job)
+	}
+
+	return nil
+}
+
+// List returns every registered spec, sorted by job.
+func (m *ManifestRegistry) List() []WorkflowSpec {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	specs := make([]WorkflowSpec, 0, len(m.specs))
+	for _, spec := range m.specs {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Job < specs[j].Job })
+	return specs
+}
+
+// ParseWorkflowSpec decodes a WorkflowSpec from JSON or YAML. Both formats
+// use the same field names (YAML is a superset of JSON), so a single
+// yaml.Unmarshal call handles both.
+func ParseWorkflowSpec(data []byte) (WorkflowSpec, error) {
+	var spec WorkflowSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return WorkflowSpec{}, fmt.Errorf("failed to decode workflow spec: %w", err)
+	}
+	return spec, nil
+}
+
+// manifestWorkflow is the Workflow implementation compiled from a
+// WorkflowSpec. It follows the same download/process/upload shape as
+// ThumbnailWorkflow, except the steps in between are data, not code.
+type manifestWorkflow struct {
+	spec          WorkflowSpec
+	contentReader ContentReader
+	derivedWriter DerivedWriter
+}
+
+func (w *manifestWorkflow) Name() string {
+	return fmt.Sprintf("ManifestWorkflow(%s)", w.spec.Job)
+}
+
+// manifestState carries data between steps of a compiled workflow.
+type manifestState struct {
+	data     []byte
+	fileName string
+	mimeType string
+}
+
+func (w *manifestWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, error) {
+	log.Printf("[%s] Starting manifest workflow %s for content_id=%s", wctx.RunID, w.Name(), wctx.Request.ContentID)
+
+	derivedType := w.spec.DerivedType
+	derivedVersion := wctx.Request.Versions[derivedType]
+	if derivedVersion < 1 {
+		err := fmt.Errorf("derived version for %q not provided in versions map", derivedType)
+		return &WorkflowResult{Success: false, Error: err}, err
+	}
+
+	hasDerived, err := w.derivedWriter.HasDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion)
+	if err != nil {
+		log.Printf("[%s] Failed to check derived content: %v", wctx.RunID, err)
+	} else if hasDerived {
+		log.Printf("[%s] Derived content already exists (type=%s, version=%d) - skipping", wctx.RunID, derivedType, derivedVersion)
+		return &WorkflowResult{
+			Success: true,
+			Outputs: map[string]interface{}{
+				"content_id":   wctx.Request.ContentID,
+				"derived_type": derivedType,
+				"version":      derivedVersion,
+				"skipped":      true,
+			},
+		}, nil
+	}
+
+	state := &manifestState{
+		fileName: fmt.Sprintf("%s_v%d", derivedType, derivedVersion),
+		mimeType: "application/octet-stream",
+	}
+
+	for i, step := range w.spec.Steps {
+		wctx.Progress.Emit(step.Type, 0, fmt.Sprintf("running step %d/%d: %s", i+1, len(w.spec.Steps), step.Type))
+
+		if err := w.runStep(wctx, step, state, derivedType, derivedVersion); err != nil {
+			log.Printf("[%s] Step %d (%s) failed: %v", wctx.RunID, i, step.Type, err)
+			return &WorkflowResult{
+				Success: false,
+				Error:   fmt.Errorf("step %d (%s) failed: %w", i, step.Type, err),
+			}, err
+		}
+
+		wctx.Progress.Emit(step.Type, 100, fmt.Sprintf("step %d/%d complete: %s", i+1, len(w.spec.Steps), step.Type))
+	}
+
+	log.Printf("[%s] Manifest workflow %s completed successfully", wctx.RunID, w.Name())
+
+	return &WorkflowResult{
+		Success: true,
+		Outputs: map[string]interface{}{
+			"content_id":   wctx.Request.ContentID,
+			"derived_type": derivedType,
+			"version":      derivedVersion,
+		},
+	}, nil
+}
+
+// runStep executes a single step, retrying per step.Retry on failure. Uses
+// retry.Do rather than a bare loop so a cancelled wctx.Ctx (DBOS-initiated
+// or otherwise) stops the step promptly between attempts instead of riding
+// out the full backoff, matching WorkflowRunner.Cancel's documented
+// contract and every other workflow in this package.
+func (w *manifestWorkflow) runStep(wctx *WorkflowContext, step StepSpec, state *manifestState, derivedType string, derivedVersion int) error {
+	policy := retry.Policy{MaxAttempts: 1}
+	var backoff time.Duration
+	if step.Retry != nil {
+		backoff = step.Retry.Backoff
+		policy = retry.Policy{
+			MaxAttempts:    step.Retry.MaxAttempts,
+			InitialBackoff: backoff,
+			MaxBackoff:     backoff,
+			Multiplier:     1,
+		}
+	}
+
+	_, err := retry.Do(wctx.Ctx, policy, func(ctx context.Context) error {
+		return w.execStep(ctx, step, state, wctx.Request, derivedType, derivedVersion)
+	}, func(attempt int, stepErr error) {
+		log.Printf("[%s] Step %q attempt %d/%d failed: %v (retrying in %s)", wctx.RunID, step.Type, attempt, policy.MaxAttempts, stepErr, backoff)
+	})
+	return err
+}
+
+func (w *manifestWorkflow) execStep(ctx context.Context, step StepSpec, state *manifestState, req pipeline.ProcessRequest, derivedType string, derivedVersion int) error {
+	switch step.Type {
+	case StepDownload:
+		exists, err := w.contentReader.Exists(ctx, req.ContentID)
+		if err != nil {
+			return fmt.Errorf("content check failed: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("source content not found: %s", req.ContentID)
+		}
+
+		reader, err := w.contentReader.GetReaderByContentID(ctx, req.ContentID)
+		if err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read downloaded content: %w", err)
+		}
+		state.data = data
+		return nil
+
+	case StepTransform:
+		return applyTransform(state, step.Config)
+
+	case StepUpload:
+		fileName := state.fileName
+		if name, ok := step.Config["file_name"].(string); ok && name != "" {
+			fileName = name
+		}
+		mimeType := state.mimeType
+		if mt, ok := step.Config["mime_type"].(string); ok && mt != "" {
+			mimeType = mt
+		}
+
+		meta := map[string]string{
+			"file_name": fileName,
+			"mime_type": mimeType,
+		}
+
+		_, err := w.derivedWriter.PutDerived(ctx, req.ContentID, derivedType, derivedVersion, bytes.NewReader(state.data), meta)
+		if err != nil {
+			return fmt.Errorf("derived write failed: %w", err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown step type %q", step.Type)
+	}
+}
+
+// applyTransform runs a built-in transform named by config["op"] against
+// state.data. Transforms are intentionally small and generic; workflows
+// that need real media processing (resizing, OCR, object detection) should
+// stay hand-written Go workflows rather than manifest steps.
+func applyTransform(state *manifestState, cfg map[string]interface{}) error {
+	op, _ := cfg["op"].(string)
+	switch op {
+	case "", "identity":
+		return nil
+
+	case "json_wrap":
+		field, _ := cfg["field"].(string)
+		if field == "" {
+			field = "data"
+		}
+		wrapped, err := json.Marshal(map[string]string{field: string(state.data)})
+		if err != nil {
+			return fmt.Errorf("json_wrap failed: %w", err)
+		}
+		state.data = wrapped
+		state.mimeType = "application/json"
+		return nil
+
+	default:
+		return fmt.Errorf("unknown transform op %q", op)
+	}
+}
@@ -0,0 +1,165 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/tendant/simple-content-pipeline/internal/detection"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// Detector locates objects in image content.
+type Detector interface {
+	Detect(ctx context.Context, r io.Reader) ([]detection.Detection, error)
+}
+
+// ObjectDetectionWorkflow finds objects in content via a pluggable Detector.
+type ObjectDetectionWorkflow struct {
+	contentReader ContentReader
+	derivedWriter DerivedWriter
+	detector      Detector
+}
+
+// NewObjectDetectionWorkflow creates a new object detection workflow
+func NewObjectDetectionWorkflow(contentReader ContentReader, derivedWriter DerivedWriter, detector Detector) *ObjectDetectionWorkflow {
+	return &ObjectDetectionWorkflow{
+		contentReader: contentReader,
+		derivedWriter: derivedWriter,
+		detector:      detector,
+	}
+}
+
+// Name returns the workflow name
+func (w *ObjectDetectionWorkflow) Name() string {
+	return "ObjectDetectionWorkflow"
+}
+
+// Execute runs the object detection workflow
+func (w *ObjectDetectionWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, error) {
+	log.Printf("[%s] Starting object detection workflow for content_id=%s", wctx.RunID, wctx.Request.ContentID)
+
+	if err := w.validateRequest(&wctx.Request); err != nil {
+		log.Printf("[%s] Validation failed: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("validation failed: %w", err),
+		}, err
+	}
+
+	derivedType := pipeline.DerivedTypeObjectDetections
+	derivedVersion := wctx.Request.Versions[derivedType]
+
+	hasDerived, err := w.derivedWriter.HasDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion)
+	if err != nil {
+		log.Printf("[%s] Failed to check derived content: %v", wctx.RunID, err)
+		// Continue anyway - don't fail on check error
+	} else if hasDerived {
+		log.Printf("[%s] Derived content already exists (type=%s, version=%d) - skipping", wctx.RunID, derivedType, derivedVersion)
+		return &WorkflowResult{
+			Success: true,
+			Outputs: map[string]interface{}{
+				"content_id":   wctx.Request.ContentID,
+				"derived_type": derivedType,
+				"version":      derivedVersion,
+				"skipped":      true,
+			},
+		}, nil
+	}
+
+	exists, err := w.contentReader.Exists(wctx.Ctx, wctx.Request.ContentID)
+	if err != nil {
+		log.Printf("[%s] Failed to check content existence: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("content check failed: %w", err),
+		}, err
+	}
+	if !exists {
+		log.Printf("[%s] Source content not found: %s", wctx.RunID, wctx.Request.ContentID)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("source content not found: %s", wctx.Request.ContentID),
+		}, nil
+	}
+
+	wctx.Progress.Emit("download", 0, "downloading source content")
+	reader, err := w.contentReader.GetReaderByContentID(wctx.Ctx, wctx.Request.ContentID)
+	if err != nil {
+		log.Printf("[%s] Failed to download source content: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("download failed: %w", err),
+		}, err
+	}
+	defer reader.Close()
+	wctx.Progress.Emit("download", 100, "source content downloaded")
+
+	wctx.Progress.Emit("detect", 0, "running object detection")
+	detections, err := w.detector.Detect(wctx.Ctx, reader)
+	if err != nil {
+		log.Printf("[%s] Object detection failed: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("object detection failed: %w", err),
+		}, err
+	}
+	wctx.Progress.Emit("detect", 100, fmt.Sprintf("found %d object(s)", len(detections)))
+	log.Printf("[%s] Object detection found %d object(s)", wctx.RunID, len(detections))
+
+	output, err := json.Marshal(map[string]interface{}{
+		"detections": detections,
+	})
+	if err != nil {
+		log.Printf("[%s] Failed to encode detections: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("encode failed: %w", err),
+		}, err
+	}
+
+	meta := map[string]string{
+		"file_name": fmt.Sprintf("object_detections_v%d.json", derivedVersion),
+		"mime_type": "application/json",
+	}
+
+	wctx.Progress.Emit("upload", 0, "uploading derived content")
+	derivedID, err := w.derivedWriter.PutDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion, bytes.NewReader(output), meta)
+	if err != nil {
+		log.Printf("[%s] Failed to write derived content: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("derived write failed: %w", err),
+		}, err
+	}
+	wctx.Progress.Emit("upload", 100, "upload complete")
+
+	log.Printf("[%s] Derived content written: %s", wctx.RunID, derivedID)
+	log.Printf("[%s] Object detection workflow completed successfully", wctx.RunID)
+
+	return &WorkflowResult{
+		Success: true,
+		Outputs: map[string]interface{}{
+			"content_id":   wctx.Request.ContentID,
+			"derived_id":   derivedID,
+			"derived_type": derivedType,
+			"version":      derivedVersion,
+			"object_count": len(detections),
+		},
+	}, nil
+}
+
+// validateRequest validates the workflow request
+func (w *ObjectDetectionWorkflow) validateRequest(req *pipeline.ProcessRequest) error {
+	version, ok := req.Versions[pipeline.DerivedTypeObjectDetections]
+	if !ok {
+		return fmt.Errorf("object detection version not provided in versions map")
+	}
+	if version < 1 {
+		return fmt.Errorf("invalid object detection version: %d", version)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// Recognizer extracts text from image content (OCR).
+type Recognizer interface {
+	Recognize(ctx context.Context, r io.Reader) (string, error)
+}
+
+// OCRWorkflow extracts text from content via a pluggable Recognizer.
+type OCRWorkflow struct {
+	contentReader ContentReader
+	derivedWriter DerivedWriter
+	recognizer    Recognizer
+}
+
+// NewOCRWorkflow creates a new OCR workflow
+func NewOCRWorkflow(contentReader ContentReader, derivedWriter DerivedWriter, recognizer Recognizer) *OCRWorkflow {
+	return &OCRWorkflow{
+		contentReader: contentReader,
+		derivedWriter: derivedWriter,
+		recognizer:    recognizer,
+	}
+}
+
+// Name returns the workflow name
+func (w *OCRWorkflow) Name() string {
+	return "OCRWorkflow"
+}
+
+// Execute runs the OCR workflow
+func (w *OCRWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, error) {
+	log.Printf("[%s] Starting OCR workflow for content_id=%s", wctx.RunID, wctx.Request.ContentID)
+
+	if err := w.validateRequest(&wctx.Request); err != nil {
+		log.Printf("[%s] Validation failed: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("validation failed: %w", err),
+		}, err
+	}
+
+	derivedType := pipeline.DerivedTypeOCRText
+	derivedVersion := wctx.Request.Versions[derivedType]
+
+	hasDerived, err := w.derivedWriter.HasDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion)
+	if err != nil {
+		log.Printf("[%s] Failed to check derived content: %v", wctx.RunID, err)
+		// Continue anyway - don't fail on check error
+	} else if hasDerived {
+		log.Printf("[%s] Derived content already exists (type=%s, version=%d) - skipping", wctx.RunID, derivedType, derivedVersion)
+		return &WorkflowResult{
+			Success: true,
+			Outputs: map[string]interface{}{
+				"content_id":   wctx.Request.ContentID,
+				"derived_type": derivedType,
+				"version":      derivedVersion,
+				"skipped":      true,
+			},
+		}, nil
+	}
+
+	exists, err := w.contentReader.Exists(wctx.Ctx, wctx.Request.ContentID)
+	if err != nil {
+		log.Printf("[%s] Failed to check content existence: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("content check failed: %w", err),
+		}, err
+	}
+	if !exists {
+		log.Printf("[%s] Source content not found: %s", wctx.RunID, wctx.Request.ContentID)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("source content not found: %s", wctx.Request.ContentID),
+		}, nil
+	}
+
+	wctx.Progress.Emit("download", 0, "downloading source content")
+	reader, err := w.contentReader.GetReaderByContentID(wctx.Ctx, wctx.Request.ContentID)
+	if err != nil {
+		log.Printf("[%s] Failed to download source content: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("download failed: %w", err),
+		}, err
+	}
+	defer reader.Close()
+	wctx.Progress.Emit("download", 100, "source content downloaded")
+
+	wctx.Progress.Emit("recognize", 0, "running OCR")
+	text, err := w.recognizer.Recognize(wctx.Ctx, reader)
+	if err != nil {
+		log.Printf("[%s] OCR recognition failed: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("ocr recognition failed: %w", err),
+		}, err
+	}
+	wctx.Progress.Emit("recognize", 100, fmt.Sprintf("recognized %d characters", len(text)))
+	log.Printf("[%s] OCR recognized %d characters", wctx.RunID, len(text))
+
+	output, err := json.Marshal(map[string]interface{}{
+		"text":       text,
+		"char_count": len(text),
+	})
+	if err != nil {
+		log.Printf("[%s] Failed to encode OCR result: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("encode failed: %w", err),
+		}, err
+	}
+
+	meta := map[string]string{
+		"file_name": fmt.Sprintf("ocr_v%d.json", derivedVersion),
+		"mime_type": "application/json",
+	}
+
+	wctx.Progress.Emit("upload", 0, "uploading derived content")
+	derivedID, err := w.derivedWriter.PutDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion, bytes.NewReader(output), meta)
+	if err != nil {
+		log.Printf("[%s] Failed to write derived content: %v", wctx.RunID, err)
+		return &WorkflowResult{
+			Success: false,
+			Error:   fmt.Errorf("derived write failed: %w", err),
+		}, err
+	}
+	wctx.Progress.Emit("upload", 100, "upload complete")
+
+	log.Printf("[%s] Derived content written: %s", wctx.RunID, derivedID)
+	log.Printf("[%s] OCR workflow completed successfully", wctx.RunID)
+
+	return &WorkflowResult{
+		Success: true,
+		Outputs: map[string]interface{}{
+			"content_id":   wctx.Request.ContentID,
+			"derived_id":   derivedID,
+			"derived_type": derivedType,
+			"version":      derivedVersion,
+			"char_count":   len(text),
+		},
+	}, nil
+}
+
+// validateRequest validates the workflow request
+func (w *OCRWorkflow) validateRequest(req *pipeline.ProcessRequest) error {
+	version, ok := req.Versions[pipeline.DerivedTypeOCRText]
+	if !ok {
+		return fmt.Errorf("ocr version not provided in versions map")
+	}
+	if version < 1 {
+		return fmt.Errorf("invalid ocr version: %d", version)
+	}
+	return nil
+}
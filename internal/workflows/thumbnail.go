@@ -3,17 +3,21 @@ package workflows
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"image"
-	"image/jpeg"
-	_ "image/png"  // Register PNG decoder
-	_ "image/gif"  // Register GIF decoder
+	_ "image/gif" // Register GIF decoder
+	_ "image/png" // Register PNG decoder
 	"io"
 	"log"
 	"strconv"
 
-	"github.com/disintegration/imaging"
+	"github.com/tendant/simple-content-pipeline/internal/progress"
+	"github.com/tendant/simple-content-pipeline/internal/workflows/imageops"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline/retry"
 )
 
 // ContentReader interface for reading content
@@ -26,8 +30,28 @@ type ContentReader interface {
 type DerivedWriter interface {
 	HasDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int) (bool, error)
 	PutDerived(ctx context.Context, contentID string, derivedType string, derivedVersion int, r io.Reader, meta map[string]string) (string, error)
+
+	// StatByDigest probes for a derived blob already produced for digest (a
+	// hash of the source bytes plus the exact parameters used to derive
+	// them, see thumbnailDigest), regardless of which content or workflow
+	// run produced it. Returns the existing derived content ID if found.
+	StatByDigest(ctx context.Context, digest string) (bool, string, error)
+
+	// RegisterDigest records that derivedContentID is the blob produced for
+	// digest, so a later StatByDigest for the same digest can reuse it.
+	RegisterDigest(ctx context.Context, digest string, derivedContentID string) error
 }
 
+// Fixed thumbnail encode parameters. These (plus width/height) are folded
+// into thumbnailDigest, so changing any of them gives subsequent thumbnails
+// a fresh digest instead of colliding with blobs encoded under the old
+// settings.
+const (
+	thumbnailQuality      = 80
+	thumbnailFilterName   = "lanczos"
+	thumbnailOutputFormat = "jpeg"
+)
+
 // ThumbnailWorkflow generates thumbnails for content
 type ThumbnailWorkflow struct {
 	contentReader ContentReader
@@ -63,31 +87,35 @@ func (w *ThumbnailWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, err
 	derivedType := pipeline.DerivedTypeThumbnail
 	derivedVersion := wctx.Request.Versions[derivedType]
 
-	// Step 2: Check if derived content already exists (skip if present)
-	hasDerived, err := w.derivedWriter.HasDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion)
-	if err != nil {
-		log.Printf("[%s] Failed to check derived content: %v", wctx.RunID, err)
-		// Continue anyway - don't fail on check error
-	} else if hasDerived {
-		log.Printf("[%s] Derived content already exists (type=%s, version=%d) - skipping", wctx.RunID, derivedType, derivedVersion)
-		return &WorkflowResult{
-			Success: true,
-			Outputs: map[string]interface{}{
-				"content_id":   wctx.Request.ContentID,
-				"derived_type": derivedType,
-				"version":      derivedVersion,
-				"skipped":      true,
-			},
-		}, nil
+	// Coarse step-based progress, distinct from the fine-grained phase/byte
+	// events reported via wctx.Progress below: "step 2 of 4: decode" rather
+	// than "42% through decode".
+	wctx.ProgressReporter.SetTotal(4)
+
+	var totalAttempts int
+	onRetry := func(step string) func(attempt int, stepErr error) {
+		return func(attempt int, stepErr error) {
+			log.Printf("[%s] %s attempt %d failed, retrying: %v", wctx.RunID, step, attempt, stepErr)
+		}
 	}
 
-	// Step 3: Check if source content exists
-	exists, err := w.contentReader.Exists(wctx.Ctx, wctx.Request.ContentID)
+	// Step 2: Check if source content exists
+	var exists bool
+	attempts, err := retry.Do(wctx.Ctx, wctx.RetryPolicy, func(ctx context.Context) error {
+		e, existsErr := w.contentReader.Exists(ctx, wctx.Request.ContentID)
+		if existsErr != nil {
+			return existsErr
+		}
+		exists = e
+		return nil
+	}, onRetry("exists"))
+	totalAttempts += attempts
 	if err != nil {
 		log.Printf("[%s] Failed to check content existence: %v", wctx.RunID, err)
 		return &WorkflowResult{
-			Success: false,
-			Error:   fmt.Errorf("content check failed: %w", err),
+			Success:  false,
+			Error:    fmt.Errorf("content check failed: %w", err),
+			Attempts: totalAttempts,
 		}, err
 	}
 
@@ -101,24 +129,37 @@ func (w *ThumbnailWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, err
 
 	log.Printf("[%s] Source content exists: %s", wctx.RunID, wctx.Request.ContentID)
 
-	// Step 4: Download source content
-	reader, err := w.contentReader.GetReaderByContentID(wctx.Ctx, wctx.Request.ContentID)
+	if wctx.Ctx.Err() != nil {
+		log.Printf("[%s] Workflow cancelled before fetch", wctx.RunID)
+		return &WorkflowResult{Success: false, Error: ErrCancelled}, ErrCancelled
+	}
+
+	// Step 5: Download source content
+	wctx.ProgressReporter.Step("fetch", 1)
+	wctx.Progress.Emit("download", 0, "downloading source content")
+	var reader io.ReadCloser
+	attempts, err = retry.Do(wctx.Ctx, wctx.RetryPolicy, func(ctx context.Context) error {
+		r, downloadErr := w.contentReader.GetReaderByContentID(ctx, wctx.Request.ContentID)
+		if downloadErr != nil {
+			return downloadErr
+		}
+		reader = r
+		return nil
+	}, onRetry("download"))
+	totalAttempts += attempts
 	if err != nil {
 		log.Printf("[%s] Failed to download source content: %v", wctx.RunID, err)
 		return &WorkflowResult{
-			Success: false,
-			Error:   fmt.Errorf("download failed: %w", err),
+			Success:  false,
+			Error:    fmt.Errorf("download failed: %w", err),
+			Attempts: totalAttempts,
 		}, err
 	}
 	defer reader.Close()
 
-	log.Printf("[%s] Source content downloaded successfully", wctx.RunID)
-
-	// Step 5: Generate thumbnail - actual implementation
-	log.Printf("[%s] Starting thumbnail generation", wctx.RunID)
-
-	// Read image data
-	imageData, err := io.ReadAll(reader)
+	// Read image data, reporting byte-count progress as it downloads (total
+	// size isn't known up front, so pct stays unknown until decode).
+	imageData, err := io.ReadAll(progress.NewReader(reader, wctx.Progress, "download", 0))
 	if err != nil {
 		log.Printf("[%s] Failed to read image data: %v", wctx.RunID, err)
 		return &WorkflowResult{
@@ -126,19 +167,12 @@ func (w *ThumbnailWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, err
 			Error:   fmt.Errorf("image read failed: %w", err),
 		}, err
 	}
+	wctx.Progress.Emit("download", 100, fmt.Sprintf("downloaded %d bytes", len(imageData)))
+	log.Printf("[%s] Source content downloaded successfully", wctx.RunID)
 
-	// Decode source image
-	img, format, err := image.Decode(bytes.NewReader(imageData))
-	if err != nil {
-		log.Printf("[%s] Failed to decode image: %v", wctx.RunID, err)
-		return &WorkflowResult{
-			Success: false,
-			Error:   fmt.Errorf("image decode failed: %w", err),
-		}, err
-	}
-	log.Printf("[%s] Image decoded successfully, format: %s", wctx.RunID, format)
-
-	// Parse dimensions from metadata (default 300x300)
+	// Parse the legacy single-rendition target dimensions from metadata
+	// (default 300x300), used when the request doesn't supply an explicit
+	// Renditions fan-out list.
 	width := 300
 	height := 300
 	if wctx.Request.Metadata != nil {
@@ -153,59 +187,277 @@ func (w *ThumbnailWorkflow) Execute(wctx *WorkflowContext) (*WorkflowResult, err
 			}
 		}
 	}
-	log.Printf("[%s] Target dimensions: %dx%d", wctx.RunID, width, height)
 
-	// Generate thumbnail using Lanczos resampling
-	thumbnail := imaging.Fit(img, width, height, imaging.Lanczos)
+	renditions := wctx.Request.Renditions
+	if len(renditions) == 0 {
+		renditions = []pipeline.Rendition{{
+			Width:   width,
+			Height:  height,
+			Format:  thumbnailOutputFormat,
+			Quality: thumbnailQuality,
+			Fit:     "fit",
+		}}
+	}
+	log.Printf("[%s] Generating %d rendition(s)", wctx.RunID, len(renditions))
 
-	// Get actual dimensions
-	bounds := thumbnail.Bounds()
-	actualWidth := bounds.Dx()
-	actualHeight := bounds.Dy()
-	log.Printf("[%s] Thumbnail generated: %dx%d", wctx.RunID, actualWidth, actualHeight)
+	if wctx.Ctx.Err() != nil {
+		log.Printf("[%s] Workflow cancelled before decode", wctx.RunID)
+		return &WorkflowResult{Success: false, Error: ErrCancelled}, ErrCancelled
+	}
 
-	// Encode as JPEG with quality 80
-	var buf bytes.Buffer
-	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 80}); err != nil {
-		log.Printf("[%s] Failed to encode JPEG: %v", wctx.RunID, err)
+	// Step 6: Decode source image once; every rendition below starts from
+	// the same decoded image. A decode failure is permanent - the bytes we
+	// downloaded won't become a valid image on a later attempt - so it
+	// short-circuits retry.Do instead of burning through MaxAttempts.
+	wctx.ProgressReporter.Step("decode", 2)
+	wctx.Progress.Emit("decode", 0, "decoding image")
+	var img image.Image
+	var format string
+	attempts, err = retry.Do(wctx.Ctx, wctx.RetryPolicy, func(ctx context.Context) error {
+		decoded, decodedFormat, decodeErr := image.Decode(bytes.NewReader(imageData))
+		if decodeErr != nil {
+			return permanent(decodeErr)
+		}
+		img, format = decoded, decodedFormat
+		return nil
+	}, onRetry("decode"))
+	totalAttempts += attempts
+	if err != nil {
+		log.Printf("[%s] Failed to decode image: %v", wctx.RunID, err)
 		return &WorkflowResult{
-			Success: false,
-			Error:   fmt.Errorf("JPEG encode failed: %w", err),
+			Success:  false,
+			Error:    fmt.Errorf("image decode failed: %w", err),
+			Attempts: totalAttempts,
 		}, err
 	}
-	log.Printf("[%s] Thumbnail encoded as JPEG, size: %d bytes", wctx.RunID, buf.Len())
+	wctx.Progress.Emit("decode", 100, fmt.Sprintf("decoded %s image", format))
+	log.Printf("[%s] Image decoded successfully, format: %s", wctx.RunID, format)
 
-	// Step 6: Write derived content
-	meta := map[string]string{
-		"file_name":  fmt.Sprintf("thumbnail_v%d.jpg", derivedVersion),
-		"width":      strconv.Itoa(actualWidth),
-		"height":     strconv.Itoa(actualHeight),
-		"mime_type":  "image/jpeg",
+	if wctx.Ctx.Err() != nil {
+		log.Printf("[%s] Workflow cancelled before resize", wctx.RunID)
+		return &WorkflowResult{Success: false, Error: ErrCancelled}, ErrCancelled
 	}
 
-	derivedID, err := w.derivedWriter.PutDerived(wctx.Ctx, wctx.Request.ContentID, derivedType, derivedVersion, &buf, meta)
-	if err != nil {
-		log.Printf("[%s] Failed to write derived content: %v", wctx.RunID, err)
-		return &WorkflowResult{
-			Success: false,
-			Error:   fmt.Errorf("derived write failed: %w", err),
-		}, err
+	// Step 7: Run every rendition's own claim/dedupe/resize/encode/upload,
+	// keyed under its own derived type so a fan-out run's renditions don't
+	// collide with each other or with a plain (non-fan-out) thumbnail run.
+	outputs := map[string]interface{}{
+		"content_id":   wctx.Request.ContentID,
+		"derived_type": derivedType,
+		"version":      derivedVersion,
+	}
+	wctx.ProgressReporter.Step("resize", 3)
+	anySucceeded := false
+	for _, rendition := range renditions {
+		name := rendition.Name
+		if name == "" {
+			name = "default"
+		}
+		wctx.ProgressReporter.Message(fmt.Sprintf("processing rendition %q", name))
+		result, attempts, err := w.executeRendition(wctx, img, imageData, derivedType, derivedVersion, rendition)
+		totalAttempts += attempts
+		if errors.Is(err, ErrCancelled) {
+			log.Printf("[%s] Workflow cancelled during rendition %q", wctx.RunID, name)
+			return &WorkflowResult{Success: false, Error: ErrCancelled}, ErrCancelled
+		}
+		if err != nil {
+			log.Printf("[%s] rendition %q failed: %v", wctx.RunID, name, err)
+			outputs[name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+		outputs[name] = result
+		anySucceeded = true
 	}
+	outputs["retry_attempts"] = totalAttempts
 
-	log.Printf("[%s] Derived content written: %s", wctx.RunID, derivedID)
-	log.Printf("[%s] Thumbnail workflow completed successfully", wctx.RunID)
+	log.Printf("[%s] Thumbnail workflow completed (%d rendition(s) attempted)", wctx.RunID, len(renditions))
 
 	return &WorkflowResult{
-		Success: true,
-		Outputs: map[string]interface{}{
-			"content_id":   wctx.Request.ContentID,
-			"derived_id":   derivedID,
-			"derived_type": derivedType,
-			"version":      derivedVersion,
-		},
+		Success:  anySucceeded,
+		Outputs:  outputs,
+		Attempts: totalAttempts,
 	}, nil
 }
 
+// executeRendition runs the claim/dedupe/generate/upload sequence for a
+// single rendition of an already-decoded source image, returning its
+// output fields (derived_id, deduped, ...) and the retry attempts it spent.
+func (w *ThumbnailWorkflow) executeRendition(wctx *WorkflowContext, img image.Image, sourceBytes []byte, derivedType string, derivedVersion int, rendition pipeline.Rendition) (map[string]interface{}, int, error) {
+	name := rendition.Name
+	if name == "" {
+		name = "default"
+	}
+	fullDerivedType := derivedType
+	if rendition.Name != "" {
+		fullDerivedType = fmt.Sprintf("%s_%s", derivedType, rendition.Name)
+	}
+
+	mime, err := imageops.MimeForFormat(rendition.Format)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	quality := rendition.Quality
+	if quality == 0 {
+		quality = thumbnailQuality
+	}
+	fit := rendition.Fit
+	if fit == "" {
+		fit = "fit"
+	}
+
+	var totalAttempts int
+	onRetry := func(step string) func(attempt int, stepErr error) {
+		return func(attempt int, stepErr error) {
+			log.Printf("[%s] rendition=%s %s attempt %d failed, retrying: %v", wctx.RunID, name, step, attempt, stepErr)
+		}
+	}
+
+	// Claim this (content, type, version) before checking whether it's
+	// already derived, so a racing execution of the same rendition (e.g.
+	// two workers picking up the same intent) waits for us instead of both
+	// generating and uploading.
+	derivedKey := fmt.Sprintf("%s:%s:%d", wctx.Request.ContentID, fullDerivedType, derivedVersion)
+	owner, release := wctx.DerivedGuard.Claim(derivedKey)
+	if !owner {
+		return map[string]interface{}{"skipped": true, "coalesced": true}, 0, nil
+	}
+	defer release()
+
+	hasDerived, err := w.derivedWriter.HasDerived(wctx.Ctx, wctx.Request.ContentID, fullDerivedType, derivedVersion)
+	if err != nil {
+		log.Printf("[%s] rendition=%s failed to check derived content: %v", wctx.RunID, name, err)
+		// Continue anyway - don't fail on check error
+	} else if hasDerived {
+		return map[string]interface{}{"skipped": true}, 0, nil
+	}
+
+	// Content-addressable check. The digest covers the source bytes plus
+	// every parameter that affects the output, so two renditions that would
+	// produce byte-identical output - even for different content IDs, e.g.
+	// the same file uploaded twice - share one encoded blob instead of each
+	// re-running resize/encode.
+	digest := thumbnailDigest(sourceBytes, rendition.Width, rendition.Height, quality, fit, mime)
+	if found, existingID, statErr := w.derivedWriter.StatByDigest(wctx.Ctx, digest); statErr != nil {
+		log.Printf("[%s] rendition=%s digest probe failed, falling back to full generation: %v", wctx.RunID, name, statErr)
+	} else if found {
+		derivedID, mountErr := w.mountDerived(wctx, existingID, fullDerivedType, derivedVersion, mime)
+		if mountErr != nil {
+			log.Printf("[%s] rendition=%s failed to mount existing blob %s, falling back to full generation: %v", wctx.RunID, name, existingID, mountErr)
+		} else {
+			return map[string]interface{}{
+				"derived_id":   derivedID,
+				"derived_type": fullDerivedType,
+				"deduped":      true,
+			}, 0, nil
+		}
+	}
+
+	pipe := imageops.Pipeline{Ops: []imageops.Op{renditionOp(rendition, fit)}}
+	thumbnail, err := pipe.Run(img)
+	if err != nil {
+		return nil, totalAttempts, fmt.Errorf("transform failed: %w", err)
+	}
+	bounds := thumbnail.Bounds()
+	actualWidth, actualHeight := bounds.Dx(), bounds.Dy()
+
+	encoder, err := imageops.EncoderFor(mime)
+	if err != nil {
+		return nil, totalAttempts, err
+	}
+
+	var buf bytes.Buffer
+	encodeWriter := progress.NewWriter(&buf, wctx.Progress, "encode:"+name, 0)
+	if err := encoder.Encode(encodeWriter, thumbnail, quality); err != nil {
+		return nil, totalAttempts, fmt.Errorf("encode failed: %w", err)
+	}
+	encodeWriter.Close()
+	wctx.Progress.Emit("encode:"+name, 100, fmt.Sprintf("encoded %d bytes", buf.Len()))
+
+	meta := map[string]string{
+		"file_name": fmt.Sprintf("%s_v%d.%s", fullDerivedType, derivedVersion, imageops.ExtForMime(mime)),
+		"width":     strconv.Itoa(actualWidth),
+		"height":    strconv.Itoa(actualHeight),
+		"mime_type": mime,
+	}
+
+	if wctx.Ctx.Err() != nil {
+		return nil, totalAttempts, ErrCancelled
+	}
+
+	var derivedID string
+	wctx.ProgressReporter.Step("upload", 4)
+	wctx.ProgressReporter.Message(fmt.Sprintf("uploading rendition %q", name))
+	wctx.Progress.Emit("upload:"+name, 0, "uploading derived content")
+	attempts, err := retry.Do(wctx.Ctx, wctx.RetryPolicy, func(ctx context.Context) error {
+		uploadReader := progress.NewReader(bytes.NewReader(buf.Bytes()), wctx.Progress, "upload:"+name, int64(buf.Len()))
+		id, putErr := w.derivedWriter.PutDerived(ctx, wctx.Request.ContentID, fullDerivedType, derivedVersion, uploadReader, meta)
+		if putErr != nil {
+			return putErr
+		}
+		derivedID = id
+		return nil
+	}, onRetry("put_derived"))
+	totalAttempts += attempts
+	if err != nil {
+		return nil, totalAttempts, fmt.Errorf("derived write failed: %w", err)
+	}
+	wctx.Progress.Emit("upload:"+name, 100, "upload complete")
+
+	if regErr := w.derivedWriter.RegisterDigest(wctx.Ctx, digest, derivedID); regErr != nil {
+		log.Printf("[%s] rendition=%s failed to register digest %s (non-fatal): %v", wctx.RunID, name, digest, regErr)
+	}
+
+	return map[string]interface{}{
+		"derived_id":   derivedID,
+		"derived_type": fullDerivedType,
+		"width":        actualWidth,
+		"height":       actualHeight,
+		"deduped":      false,
+	}, totalAttempts, nil
+}
+
+// renditionOp builds the resize-family Op for a rendition: "resize" scales
+// to exact Width x Height, anything else (including the default "fit")
+// scales down to fit within Width x Height preserving aspect ratio.
+func renditionOp(rendition pipeline.Rendition, fit string) imageops.Op {
+	if fit == "resize" {
+		return imageops.Resize{Width: rendition.Width, Height: rendition.Height}
+	}
+	return imageops.Fit{Width: rendition.Width, Height: rendition.Height}
+}
+
+// thumbnailDigest hashes the source image bytes together with every
+// parameter that determines the encoded output, so two renditions that
+// would produce byte-identical output - regardless of which content ID or
+// workflow run asked for them - map to the same digest.
+func thumbnailDigest(sourceBytes []byte, width, height, quality int, fit, mime string) string {
+	h := sha256.New()
+	h.Write(sourceBytes)
+	fmt.Fprintf(h, "\x00%d\x00%d\x00%d\x00%s\x00%s\x00%s", width, height, quality, fit, thumbnailFilterName, mime)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// mountDerived registers an existing derived blob (found via StatByDigest)
+// under a new logical (contentID, fullDerivedType, derivedVersion) mapping,
+// without re-running resize/encode. The backing store has no blob-level
+// link primitive, so this "mount" is a read of the already-encoded bytes
+// followed by a normal PutDerived - cheap compared to the image processing
+// it replaces, but not a zero-copy reference.
+func (w *ThumbnailWorkflow) mountDerived(wctx *WorkflowContext, existingDerivedID string, fullDerivedType string, derivedVersion int, mime string) (string, error) {
+	reader, err := w.contentReader.GetReaderByContentID(wctx.Ctx, existingDerivedID)
+	if err != nil {
+		return "", fmt.Errorf("read existing derived blob: %w", err)
+	}
+	defer reader.Close()
+
+	meta := map[string]string{
+		"file_name": fmt.Sprintf("%s_v%d.%s", fullDerivedType, derivedVersion, imageops.ExtForMime(mime)),
+		"mime_type": mime,
+	}
+	return w.derivedWriter.PutDerived(wctx.Ctx, wctx.Request.ContentID, fullDerivedType, derivedVersion, reader, meta)
+}
+
 // validateRequest validates the workflow request
 func (w *ThumbnailWorkflow) validateRequest(req *pipeline.ProcessRequest) error {
 	// content_id is validated at the HTTP handler level
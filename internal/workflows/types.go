@@ -4,11 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/dbos-inc/dbos-transact-golang/dbos"
+	"github.com/google/uuid"
 	"github.com/tendant/simple-content-pipeline/internal/dbosruntime"
+	"github.com/tendant/simple-content-pipeline/internal/deadletter"
+	"github.com/tendant/simple-content-pipeline/internal/dedupe"
+	"github.com/tendant/simple-content-pipeline/internal/idle"
+	"github.com/tendant/simple-content-pipeline/internal/progress"
+	"github.com/tendant/simple-content-pipeline/internal/progressstore"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline/retry"
+	"github.com/tendant/simple-content-pipeline/pkg/runlogs"
 )
 
 // WorkflowContext contains context for workflow execution
@@ -16,6 +26,103 @@ type WorkflowContext struct {
 	Ctx     context.Context
 	Request pipeline.ProcessRequest
 	RunID   string
+
+	// Progress reports phase/percent updates and log lines for this run to
+	// any subscribers of WorkflowRunner.Events/Logs. Never nil; workflows
+	// can call it unconditionally.
+	Progress *progress.Sink
+
+	// DerivedGuard coordinates concurrent attempts to produce the same
+	// derived output for this run (see DerivedGuard.Claim). May be nil, in
+	// which case every claim succeeds and workflows run unguarded.
+	DerivedGuard *DerivedGuard
+
+	// RetryPolicy governs retry-with-backoff for this run's I/O steps (see
+	// retry.Do). Always set by WorkflowRunner to its configured policy
+	// (retry.DefaultPolicy() unless overridden via SetRetryPolicy).
+	RetryPolicy retry.Policy
+
+	// ProgressReporter lets a workflow report coarse, step-based progress
+	// ("step 2 of 4: decode") distinct from Progress's fine-grained
+	// phase/byte events. Never nil; workflows can call it unconditionally.
+	ProgressReporter ProgressReporter
+}
+
+// ProgressReporter lets a workflow report how many steps its run has in
+// total, which one it's currently on, and a free-text status message. The
+// default implementation persists each update to a progressstore.Store, so
+// WorkflowRunner.GetStatus (and therefore GET /v1/runs/{run_id}) can surface
+// CurrentStep/StepIndex/StepCount/Message/PercentComplete even after a
+// process restart.
+type ProgressReporter interface {
+	// SetTotal declares how many steps this run will go through, e.g. 4 for
+	// a workflow that fetches, decodes, resizes, and uploads.
+	SetTotal(n int)
+
+	// Step reports that the run has reached step current (1-indexed) named
+	// name, e.g. Step("resize", 3).
+	Step(name string, current int)
+
+	// Message attaches a free-text status line to the current step, e.g.
+	// which rendition is being processed.
+	Message(s string)
+}
+
+// progressReporter is the default ProgressReporter: every update is
+// persisted to a progressstore.Store and mirrored onto the run's
+// progress.Sink, so it also shows up on the phase/byte event stream
+// consumed by GET /v1/runs/{run_id}/events.
+type progressReporter struct {
+	runID string
+	store progressstore.Store
+	sink  *progress.Sink
+
+	mu   sync.Mutex
+	snap progressstore.Snapshot
+}
+
+func newProgressReporter(runID string, store progressstore.Store, sink *progress.Sink) *progressReporter {
+	return &progressReporter{runID: runID, store: store, sink: sink}
+}
+
+func (p *progressReporter) SetTotal(n int) {
+	p.mu.Lock()
+	p.snap.StepCount = n
+	p.mu.Unlock()
+	p.save()
+}
+
+func (p *progressReporter) Step(name string, current int) {
+	p.mu.Lock()
+	p.snap.CurrentStep = name
+	p.snap.StepIndex = current
+	if p.snap.StepCount > 0 {
+		p.snap.PercentComplete = float64(current) / float64(p.snap.StepCount) * 100
+	}
+	pct := p.snap.PercentComplete
+	p.mu.Unlock()
+	p.sink.Emit(name, pct, "")
+	p.save()
+}
+
+func (p *progressReporter) Message(s string) {
+	p.mu.Lock()
+	p.snap.Message = s
+	p.mu.Unlock()
+	p.save()
+}
+
+// save persists the current snapshot, logging (rather than propagating) a
+// failure since losing a progress update shouldn't fail the workflow it
+// describes - the same reasoning WorkflowRunner.logRun applies to run log
+// entries.
+func (p *progressReporter) save() {
+	p.mu.Lock()
+	snap := p.snap
+	p.mu.Unlock()
+	if err := p.store.Save(context.Background(), p.runID, snap); err != nil {
+		log.Printf("progress: failed to persist snapshot for %s: %v", p.runID, err)
+	}
 }
 
 // WorkflowResult contains the result of workflow execution
@@ -23,6 +130,12 @@ type WorkflowResult struct {
 	Success bool
 	Error   error
 	Outputs map[string]interface{}
+
+	// Attempts is the total number of attempts (summed across every
+	// retry.Do-wrapped step) this run made. Zero means no retried step ran
+	// (e.g. the run failed validation before reaching one). Workflows that
+	// don't use retry.Do (OCR, object detection) leave it zero.
+	Attempts int
 }
 
 // Workflow defines the interface for processing workflows
@@ -36,15 +149,31 @@ type Workflow interface {
 
 // WorkflowRunner executes workflows
 type WorkflowRunner struct {
-	workflows   map[string]Workflow
-	dbosRuntime *dbosruntime.Runtime
+	workflows       map[string]Workflow
+	dbosRuntime     *dbosruntime.Runtime
+	progress        *progress.Registry
+	runLogger       *runlogs.RunLogger
+	idleTracker     *idle.Tracker
+	dispatch        *dispatchRegistry
+	derivedGuard    *DerivedGuard
+	retryPolicy     retry.Policy
+	retryPolicies   map[string]retry.Policy
+	progressStore   progressstore.Store
+	dedupeTracker   dedupe.Tracker
+	deadLetterStore deadletter.Store
 }
 
 // NewWorkflowRunner creates a new workflow runner with DBOS support
 func NewWorkflowRunner(dbosRuntime *dbosruntime.Runtime) *WorkflowRunner {
 	runner := &WorkflowRunner{
-		workflows:   make(map[string]Workflow),
-		dbosRuntime: dbosRuntime,
+		workflows:     make(map[string]Workflow),
+		dbosRuntime:   dbosRuntime,
+		progress:      progress.NewRegistry(),
+		dispatch:      newDispatchRegistry(),
+		derivedGuard:  NewDerivedGuard(),
+		retryPolicy:   retry.DefaultPolicy(),
+		retryPolicies: make(map[string]retry.Policy),
+		progressStore: progressstore.NewMemoryStore(),
 	}
 
 	// Register the DBOS workflow function
@@ -55,13 +184,149 @@ func NewWorkflowRunner(dbosRuntime *dbosruntime.Runtime) *WorkflowRunner {
 	return runner
 }
 
-// Register registers a workflow
+// Register registers a workflow using the runner's default retry policy
+// (retry.DefaultPolicy() unless overridden via SetRetryPolicy). Use
+// RegisterWithPolicy to give a workflow type its own retry behavior instead.
 func (r *WorkflowRunner) Register(job string, workflow Workflow) {
 	r.workflows[job] = workflow
 }
 
+// RegisterWithPolicy registers a workflow with a retry policy specific to
+// this job type, overriding the runner's default for every run of job (see
+// WorkflowContext.RetryPolicy). Letting e.g. object detection retry more
+// patiently than thumbnail generation is the main reason to use this
+// instead of Register.
+func (r *WorkflowRunner) RegisterWithPolicy(job string, workflow Workflow, policy retry.Policy) {
+	r.workflows[job] = workflow
+	r.retryPolicies[job] = policy
+}
+
+// policyFor returns job's registered retry policy, falling back to the
+// runner's default if job was registered with Register instead of
+// RegisterWithPolicy.
+func (r *WorkflowRunner) policyFor(job string) retry.Policy {
+	if policy, ok := r.retryPolicies[job]; ok {
+		return policy
+	}
+	return r.retryPolicy
+}
+
+// SetRunLogger attaches a RunLogger so every workflow run's start, success,
+// and failure are also persisted to disk (see logRun), in addition to being
+// published through the in-memory progress sink. Passing nil disables
+// persistence; the default runner has none.
+func (r *WorkflowRunner) SetRunLogger(logger *runlogs.RunLogger) {
+	r.runLogger = logger
+}
+
+// SetIdleTracker attaches an idle.Tracker so a shutdown path can wait for
+// in-flight workflow executions (Run and the DBOS-invoked
+// executeWorkflowDBOS) to finish before tearing down the DBOS runtime.
+// Passing nil (the default) disables tracking.
+func (r *WorkflowRunner) SetIdleTracker(tracker *idle.Tracker) {
+	r.idleTracker = tracker
+}
+
+// SetRetryPolicy overrides the retry-with-backoff policy applied to every
+// run's I/O steps (see WorkflowContext.RetryPolicy), letting an operator
+// tune retry behavior per deployment. Defaults to retry.DefaultPolicy().
+func (r *WorkflowRunner) SetRetryPolicy(policy retry.Policy) {
+	r.retryPolicy = policy
+}
+
+// SetProgressStore overrides where step-based progress snapshots are
+// persisted (see WorkflowContext.ProgressReporter), letting a DBOS-backed
+// deployment share the DBOS Postgres pool instead of the in-process default
+// (progressstore.NewMemoryStore()).
+func (r *WorkflowRunner) SetProgressStore(store progressstore.Store) {
+	r.progressStore = store
+}
+
+// SetDedupeTracker attaches a dedupe.Tracker so every run's terminal outcome
+// is recorded (see dedupe.Tracker.RecordOutcome) for a later submission of
+// the same content_id/job to consult via RecordOrSuppress. Passing nil (the
+// default) disables outcome recording; suppression then never triggers even
+// if the handler's own tracker has a SuppressionPolicy configured.
+func (r *WorkflowRunner) SetDedupeTracker(tracker dedupe.Tracker) {
+	r.dedupeTracker = tracker
+}
+
+// SetDeadLetterStore attaches a deadletter.Store so a run that fails after
+// exhausting its retry policy is recorded for GET /v1/deadletters instead of
+// its failure going unnoticed. Passing nil (the default) disables recording;
+// a failed run is then only visible through GetStatus/List like any other
+// failure.
+func (r *WorkflowRunner) SetDeadLetterStore(store deadletter.Store) {
+	r.deadLetterStore = store
+}
+
+// recordDeadLetter persists runID's terminal failure if a deadletter.Store
+// is attached and cause represents a genuine failure (not cancellation,
+// which is an operator-requested stop rather than a failure). This covers
+// both a retry policy exhausting its MaxAttempts and a permanent failure
+// that never entered a retry loop at all (e.g. a validation error) - either
+// way DBOS won't retry the run on its own, so the operator needs a record to
+// inspect and a way to resubmit it. Errors are logged rather than returned,
+// since a failure to record a dead letter shouldn't mask the workflow
+// failure it describes.
+func (r *WorkflowRunner) recordDeadLetter(req pipeline.ProcessRequest, runID string, cause error, result *WorkflowResult) {
+	if r.deadLetterStore == nil || cause == nil || errors.Is(cause, ErrCancelled) {
+		return
+	}
+
+	attempts := 0
+	if result != nil {
+		attempts = result.Attempts
+	}
+
+	now := time.Now()
+	entry := deadletter.Entry{
+		RunID:         runID,
+		Job:           req.Job,
+		Request:       req,
+		LastError:     cause.Error(),
+		Attempts:      attempts,
+		FirstFailedAt: now,
+		LastFailedAt:  now,
+	}
+	if existing, ok, err := r.deadLetterStore.Get(context.Background(), runID); err == nil && ok {
+		entry.FirstFailedAt = existing.FirstFailedAt
+	}
+	if err := r.deadLetterStore.Put(context.Background(), entry); err != nil {
+		log.Printf("deadletter: failed to record run %s: %v", runID, err)
+	}
+}
+
+// recordOutcome persists runID's terminal outcome for (contentID, job) if a
+// dedupe.Tracker is attached. Errors are logged rather than returned, since a
+// failure to record an outcome shouldn't fail the workflow it describes.
+func (r *WorkflowRunner) recordOutcome(contentID, job, runID string, cause error) {
+	if r.dedupeTracker == nil {
+		return
+	}
+	state := MapDBOSStatus(dbosCompletionStatus(cause))
+	if err := r.dedupeTracker.RecordOutcome(context.Background(), contentID, job, runID, state); err != nil {
+		log.Printf("dedupe: failed to record outcome for run %s: %v", runID, err)
+	}
+}
+
+// logRun persists a lifecycle entry for runID if a RunLogger is attached.
+// Errors are logged rather than returned, since a logging failure shouldn't
+// fail the workflow it's describing.
+func (r *WorkflowRunner) logRun(runID, level, message string) {
+	if r.runLogger == nil {
+		return
+	}
+	if err := r.runLogger.Record(runlogs.Entry{RunID: runID, Level: level, Message: message}); err != nil {
+		log.Printf("run log: failed to record entry for %s: %v", runID, err)
+	}
+}
+
 // Run executes a workflow for the given job type (synchronous - for backward compat)
 func (r *WorkflowRunner) Run(wctx *WorkflowContext) (*WorkflowResult, error) {
+	done := r.idleTracker.Inc()
+	defer done()
+
 	workflow, ok := r.workflows[wctx.Request.Job]
 	if !ok {
 		return &WorkflowResult{
@@ -70,19 +335,124 @@ func (r *WorkflowRunner) Run(wctx *WorkflowContext) (*WorkflowResult, error) {
 		}, ErrWorkflowNotFound
 	}
 
-	return workflow.Execute(wctx)
+	wctx.Progress = r.progress.Sink(wctx.RunID)
+	wctx.DerivedGuard = r.derivedGuard
+	wctx.RetryPolicy = r.policyFor(wctx.Request.Job)
+	wctx.ProgressReporter = newProgressReporter(wctx.RunID, r.progressStore, wctx.Progress)
+
+	r.logRun(wctx.RunID, "info", fmt.Sprintf("workflow %s started", workflow.Name()))
+	result, err := workflow.Execute(wctx)
+	cause := errOrResult(err, result)
+	if cause != nil {
+		r.logRun(wctx.RunID, "error", fmt.Sprintf("workflow %s failed: %v", workflow.Name(), cause))
+	} else {
+		r.logRun(wctx.RunID, "info", fmt.Sprintf("workflow %s succeeded", workflow.Name()))
+	}
+	r.recordOutcome(wctx.Request.ContentID, wctx.Request.Job, wctx.RunID, cause)
+	r.recordDeadLetter(wctx.Request, wctx.RunID, cause, result)
+	return result, err
+}
+
+// errOrResult returns err if set, otherwise result.Error, for logging a
+// failure's cause regardless of which of the two return paths carried it.
+func errOrResult(err error, result *WorkflowResult) error {
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// Events returns the buffered progress-event backlog for runID plus a
+// channel of subsequent events, for GET /v1/runs/{run_id}/events.
+func (r *WorkflowRunner) Events(runID string) ([]progress.Event, <-chan progress.Event, func()) {
+	return r.progress.SubscribeEvents(runID)
+}
+
+// Logs returns the buffered log backlog for runID plus a channel of
+// subsequent lines, for GET /v1/runs/{run_id}/logs.
+func (r *WorkflowRunner) Logs(runID string) ([]string, <-chan string, func()) {
+	return r.progress.SubscribeLogs(runID)
 }
 
-// RunAsync enqueues a workflow for async execution via DBOS
+// RunAsync enqueues a workflow for async execution via DBOS. It dedupes
+// identical submissions like RunAsyncDeduped, but drops the deduped flag
+// since most callers only care about the run ID to poll.
 func (r *WorkflowRunner) RunAsync(ctx context.Context, req pipeline.ProcessRequest) (string, error) {
+	runID, _, err := r.RunAsyncDeduped(ctx, req)
+	return runID, err
+}
+
+// RunAsyncDeduped enqueues req for async execution via DBOS, coalescing
+// identical submissions instead of enqueuing a duplicate run. Two requests
+// dedupe if dispatchKey(req) matches: same content ID, job, versions, and
+// metadata. Dedup is checked in two layers, borrowing the idea from
+// Docker's transfer manager (which attaches new watchers to an in-flight
+// transfer rather than starting a duplicate):
+//
+//   - an in-memory map of live run IDs, for two calls racing within this
+//     process before either has reached DBOS;
+//   - the deterministic workflow ID itself (dispatchKey's output), which
+//     survives a process restart because DBOS's own workflow table is
+//     queried by GetStatus before enqueuing, and because DBOS treats a
+//     second RunWorkflow call with the same ID as the same execution.
+//
+// When a submission coalesces onto an existing run, deduped is true and the
+// caller can watch it finish via Subscribe(runID) instead of polling its own
+// new run.
+func (r *WorkflowRunner) RunAsyncDeduped(ctx context.Context, req pipeline.ProcessRequest) (runID string, deduped bool, err error) {
+	if r.dbosRuntime == nil {
+		return "", false, errors.New("DBOS runtime not initialized")
+	}
+
+	key := dispatchKey(req)
+	workflowID := key
+
+	if liveID, ok := r.dispatch.liveRunID(key); ok {
+		return liveID, true, nil
+	}
+
+	if status, err := r.GetStatus(ctx, workflowID); err == nil && status.State == "succeeded" {
+		return workflowID, true, nil
+	}
+
+	r.dispatch.claim(key, workflowID)
+
+	handle, err := dbos.RunWorkflow[pipeline.ProcessRequest, *WorkflowResult](
+		r.dbosRuntime.Context(),
+		r.executeWorkflowDBOS,
+		req,
+		dbos.WithWorkflowID(workflowID),
+		dbos.WithQueue(r.dbosRuntime.QueueName()),
+	)
+	if err != nil {
+		r.dispatch.complete(key, workflowID, WorkflowStatus{RunID: workflowID, State: "failed", Error: err})
+		return "", false, err
+	}
+
+	return handle.GetWorkflowID(), false, nil
+}
+
+// RequeueAsync re-enqueues req as a brand-new run, for resubmitting a run
+// that already reached a terminal state (e.g. one HandleRequeue just loaded
+// from the dead-letter store). Unlike RunAsync/RunAsyncDeduped, it does not
+// coalesce onto dispatchKey(req): reusing that deterministic ID would hand
+// DBOS the same workflow_uuid as the failed run, and DBOS never resets a
+// row's status on conflict, so the stale terminal status would stay forever
+// and the queue's ENQUEUED-only dequeue would never pick it up again. A
+// fresh ID sidesteps that entirely.
+func (r *WorkflowRunner) RequeueAsync(ctx context.Context, req pipeline.ProcessRequest) (string, error) {
 	if r.dbosRuntime == nil {
 		return "", errors.New("DBOS runtime not initialized")
 	}
 
-	// Generate workflow ID for exactly-once semantics
-	workflowID := fmt.Sprintf("%s-%s-%d", req.Job, req.ContentID, time.Now().UnixNano())
+	key := dispatchKey(req)
+	workflowID := uuid.New().String()
+
+	r.dispatch.claim(key, workflowID)
 
-	// Enqueue workflow with DBOS (generic function with type parameters)
 	handle, err := dbos.RunWorkflow[pipeline.ProcessRequest, *WorkflowResult](
 		r.dbosRuntime.Context(),
 		r.executeWorkflowDBOS,
@@ -91,14 +461,82 @@ func (r *WorkflowRunner) RunAsync(ctx context.Context, req pipeline.ProcessReque
 		dbos.WithQueue(r.dbosRuntime.QueueName()),
 	)
 	if err != nil {
+		r.dispatch.complete(key, workflowID, WorkflowStatus{RunID: workflowID, State: "failed", Error: err})
 		return "", err
 	}
 
 	return handle.GetWorkflowID(), nil
 }
 
+// statusPollInterval is how often SubscribeStatus re-checks DBOS for runID's
+// current status. DBOS's status table is cheap to poll and
+// GET /v1/runs/{run_id}/events?type=status only needs roughly-live updates,
+// not push-exact ones, so a fixed short interval is used rather than making
+// it configurable.
+const statusPollInterval = 1 * time.Second
+
+// SubscribeStatus polls runID's DBOS status every statusPollInterval,
+// coalescing consecutive identical states so a caller only ever sees each
+// distinct state once, and closes the returned channel after delivering a
+// terminal state ("succeeded", "failed", "cancelled" or "dead_letter"). The first poll happens
+// immediately, so a caller that reconnects mid-run (or after the workflow
+// already finished) gets runID's current state right away rather than
+// waiting out the first interval.
+func (r *WorkflowRunner) SubscribeStatus(ctx context.Context, runID string) (<-chan WorkflowStatus, error) {
+	if r.dbosRuntime == nil {
+		return nil, errors.New("status tracking requires DBOS runtime")
+	}
+	// Fail fast on an unknown runID instead of spinning silently until ctx
+	// is cancelled.
+	if _, err := r.GetStatus(ctx, runID); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan WorkflowStatus, 1)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(statusPollInterval)
+		defer ticker.Stop()
+
+		var last string
+		for {
+			if status, err := r.GetStatus(ctx, runID); err == nil && status.State != last {
+				last = status.State
+				select {
+				case ch <- *status:
+				case <-ctx.Done():
+					return
+				}
+				if status.State == "succeeded" || status.State == "failed" ||
+					status.State == "cancelled" || status.State == "dead_letter" {
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Subscribe returns a channel that receives runID's terminal WorkflowStatus
+// exactly once - immediately, if it already finished, or when it does -
+// plus a cancel func to release the subscription early. Multiple callers
+// can subscribe to the same runID and each gets its own delivery, so a
+// dedup-coalesced submission can wait on the run it joined instead of
+// polling GetStatus in a loop.
+func (r *WorkflowRunner) Subscribe(runID string) (<-chan WorkflowStatus, func()) {
+	return r.dispatch.broadcastFor(runID).subscribe()
+}
+
 // executeWorkflowDBOS is the DBOS workflow function that wraps existing workflows
 func (r *WorkflowRunner) executeWorkflowDBOS(dbosCtx dbos.DBOSContext, req pipeline.ProcessRequest) (*WorkflowResult, error) {
+	done := r.idleTracker.Inc()
+	defer done()
+
 	// Get workflow by job type
 	workflow, ok := r.workflows[req.Job]
 	if !ok {
@@ -118,14 +556,52 @@ func (r *WorkflowRunner) executeWorkflowDBOS(dbosCtx dbos.DBOSContext, req pipel
 	}
 
 	// Create workflow context (DBOSContext implements context.Context)
+	sink := r.progress.Sink(workflowID)
 	wctx := &WorkflowContext{
-		Ctx:     dbosCtx,
-		Request: req,
-		RunID:   workflowID,
+		Ctx:              dbosCtx,
+		Request:          req,
+		RunID:            workflowID,
+		Progress:         sink,
+		DerivedGuard:     r.derivedGuard,
+		RetryPolicy:      r.policyFor(req.Job),
+		ProgressReporter: newProgressReporter(workflowID, r.progressStore, sink),
 	}
 
 	// Execute workflow (DBOS will checkpoint automatically)
-	return workflow.Execute(wctx)
+	r.logRun(workflowID, "info", fmt.Sprintf("workflow %s started", workflow.Name()))
+	result, err := workflow.Execute(wctx)
+	cause := errOrResult(err, result)
+	if cause != nil {
+		r.logRun(workflowID, "error", fmt.Sprintf("workflow %s failed: %v", workflow.Name(), cause))
+	} else {
+		r.logRun(workflowID, "info", fmt.Sprintf("workflow %s succeeded", workflow.Name()))
+	}
+
+	r.recordOutcome(req.ContentID, req.Job, workflowID, cause)
+	r.recordDeadLetter(req, workflowID, cause, result)
+
+	key := dispatchKey(req)
+	r.dispatch.complete(key, workflowID, WorkflowStatus{
+		RunID:  workflowID,
+		State:  MapDBOSStatus(dbosCompletionStatus(cause)),
+		Result: result,
+		Error:  cause,
+	})
+
+	return result, err
+}
+
+// dbosCompletionStatus returns the DBOS status string corresponding to a
+// just-finished workflow's cause (nil means success), so it can be passed
+// through the same MapDBOSStatus used for statuses read back from DBOS.
+func dbosCompletionStatus(cause error) string {
+	if errors.Is(cause, ErrCancelled) {
+		return "CANCELLED"
+	}
+	if cause != nil {
+		return "ERROR"
+	}
+	return "SUCCESS"
 }
 
 // WorkflowStatus represents the status of a workflow execution
@@ -136,6 +612,16 @@ type WorkflowStatus struct {
 	FinishedAt *time.Time
 	Result     *WorkflowResult
 	Error      error
+
+	// CurrentStep, StepIndex, StepCount, Message, and PercentComplete mirror
+	// the run's latest ProgressReporter update (see progressstore.Snapshot),
+	// letting a caller render a real progress bar instead of just State.
+	// Zero values mean the run hasn't reported any step-based progress yet.
+	CurrentStep     string
+	StepIndex       int
+	StepCount       int
+	Message         string
+	PercentComplete float64
 }
 
 // GetStatus retrieves the status of a workflow execution using DBOS SDK
@@ -156,35 +642,161 @@ func (r *WorkflowRunner) GetStatus(ctx context.Context, runID string) (*Workflow
 		return nil, fmt.Errorf("failed to get workflow status: %w", err)
 	}
 
-	// Convert DBOS status to our status format
-	state := mapDBOSStatus(string(dbosStatus.Status))
+	status := workflowStatusFromDBOS(dbosStatus)
+	status.RunID = runID
+	if snap, ok, err := r.progressStore.Load(ctx, runID); err == nil && ok {
+		status.CurrentStep = snap.CurrentStep
+		status.StepIndex = snap.StepIndex
+		status.StepCount = snap.StepCount
+		status.Message = snap.Message
+		status.PercentComplete = snap.PercentComplete
+	}
+	// A dead-lettered run is always a "failed" DBOS status underneath, but
+	// reports the more specific "dead_letter" state so an operator can tell
+	// "gave up after exhausting retries" apart from a transient failure.
+	if r.deadLetterStore != nil {
+		if _, ok, err := r.deadLetterStore.Get(ctx, runID); err == nil && ok {
+			status.State = "dead_letter"
+		}
+	}
+	return status, nil
+}
+
+// ListFilter narrows the workflows returned by WorkflowRunner.List.
+type ListFilter struct {
+	// Job restricts results to a single job type (e.g. "thumbnail"). Matched
+	// against the enqueued pipeline.ProcessRequest.Job field, so it only
+	// applies to workflows enqueued with input loaded.
+	Job string
+
+	// State restricts results to one workflow state: "pending", "running",
+	// "succeeded", "failed", or "cancelled" (see WorkflowStatus.State).
+	State string
+
+	// Since restricts results to workflows created at or after this time.
+	// Zero means unset.
+	Since time.Time
+
+	// Limit caps the number of results. 0 means the DBOS default.
+	Limit int
+}
+
+// List returns workflows matching filter, newest first. State, Since, and
+// Limit are pushed down to DBOS; Job is applied client-side since it lives
+// inside the enqueued pipeline.ProcessRequest rather than in DBOS's own
+// workflow metadata.
+func (r *WorkflowRunner) List(ctx context.Context, filter ListFilter) ([]WorkflowStatus, error) {
+	if r.dbosRuntime == nil {
+		return nil, errors.New("status tracking requires DBOS runtime")
+	}
+
+	opts := []dbos.ListWorkflowsOption{dbos.WithSortDesc(), dbos.WithLoadInput(true)}
+	if statuses := dbosStatusesForState(filter.State); len(statuses) > 0 {
+		opts = append(opts, dbos.WithStatus(statuses))
+	}
+	if !filter.Since.IsZero() {
+		opts = append(opts, dbos.WithStartTime(filter.Since))
+	}
+	if filter.Limit > 0 {
+		opts = append(opts, dbos.WithLimit(filter.Limit))
+	}
+
+	statuses, err := dbos.ListWorkflows(r.dbosRuntime.Context(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	out := make([]WorkflowStatus, 0, len(statuses))
+	for _, s := range statuses {
+		if filter.Job != "" {
+			req, ok := s.Input.(pipeline.ProcessRequest)
+			if !ok || req.Job != filter.Job {
+				continue
+			}
+		}
+		status := workflowStatusFromDBOS(s)
+		status.RunID = s.ID
+		out = append(out, *status)
+	}
+
+	return out, nil
+}
+
+// Cancel requests cancellation of the workflow identified by runID. DBOS
+// marks it CANCELLED and cancels the context passed to its Execute as
+// wctx.Ctx, so a workflow that checks wctx.Ctx.Err() between steps (see
+// ThumbnailWorkflow.Execute) can stop promptly and return ErrCancelled
+// instead of running to completion or its next retry. Cancel does not block
+// until that happens.
+func (r *WorkflowRunner) Cancel(ctx context.Context, runID string) error {
+	if r.dbosRuntime == nil {
+		return errors.New("cancel requires DBOS runtime")
+	}
+	if err := dbos.CancelWorkflow(r.dbosRuntime.Context(), runID); err != nil {
+		return fmt.Errorf("failed to cancel workflow %s: %w", runID, err)
+	}
+	return nil
+}
+
+// Retry resumes a cancelled or failed workflow from its last checkpoint,
+// re-enqueuing it under the same workflow ID. It returns the run ID the
+// caller can keep polling.
+func (r *WorkflowRunner) Retry(ctx context.Context, runID string) (string, error) {
+	if r.dbosRuntime == nil {
+		return "", errors.New("retry requires DBOS runtime")
+	}
+	handle, err := dbos.ResumeWorkflow[*WorkflowResult](r.dbosRuntime.Context(), runID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retry workflow %s: %w", runID, err)
+	}
+	return handle.GetWorkflowID(), nil
+}
+
+// workflowStatusFromDBOS converts a DBOS workflow status to our public
+// status format. Callers fill in RunID, since it is sourced differently
+// depending on whether the status came from a single lookup or a list.
+func workflowStatusFromDBOS(dbosStatus dbos.WorkflowStatus) *WorkflowStatus {
+	state := MapDBOSStatus(string(dbosStatus.Status))
 
-	// Determine finished time based on status
 	var finishedAt *time.Time
 	if state == "succeeded" || state == "failed" {
-		finishedAt = &dbosStatus.UpdatedAt
+		updatedAt := dbosStatus.UpdatedAt
+		finishedAt = &updatedAt
 	}
 
-	// Extract result if available (only present after successful completion)
 	var result *WorkflowResult
-	if dbosStatus.Output != nil {
-		if r, ok := dbosStatus.Output.(*WorkflowResult); ok {
-			result = r
-		}
+	if r, ok := dbosStatus.Output.(*WorkflowResult); ok {
+		result = r
 	}
 
 	return &WorkflowStatus{
-		RunID:      runID,
 		State:      state,
 		StartedAt:  dbosStatus.CreatedAt,
 		FinishedAt: finishedAt,
 		Result:     result,
 		Error:      dbosStatus.Error,
-	}, nil
+	}
+}
+
+// dbosStatusesForState maps a WorkflowStatus.State value to the underlying
+// DBOS statuses that produce it (see MapDBOSStatus).
+func dbosStatusesForState(state string) []dbos.WorkflowStatusType {
+	switch state {
+	case "pending":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusPending, dbos.WorkflowStatusEnqueued}
+	case "succeeded":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusSuccess}
+	case "failed":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusError, dbos.WorkflowStatusMaxRecoveryAttemptsExceeded}
+	case "cancelled":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusCancelled}
+	default:
+		return nil
+	}
 }
 
-// mapDBOSStatus maps DBOS status values to our status format
-func mapDBOSStatus(dbosStatus string) string {
+// MapDBOSStatus maps DBOS status values to our status format
+func MapDBOSStatus(dbosStatus string) string {
 	switch dbosStatus {
 	case "PENDING":
 		return "pending"
@@ -196,6 +808,8 @@ func mapDBOSStatus(dbosStatus string) string {
 		return "failed"
 	case "RETRIES_EXCEEDED":
 		return "failed"
+	case "CANCELLED":
+		return "cancelled"
 	default:
 		return "running"
 	}
@@ -0,0 +1,149 @@
+// Package retry provides the retry-with-backoff policy wrapped around
+// individual workflow steps (ThumbnailWorkflow.Execute's Exists,
+// GetReaderByContentID, and PutDerived calls, and their counterparts in
+// other workflows). It is distinct from pkg/retry, which covers storage and
+// HTTP client calls below the workflow layer: this package's RetryableFunc
+// lets a workflow step classify its own errors (e.g. an image decode
+// failure is permanent, a storage write timeout is transient) instead of
+// inheriting storage's HTTP-status-driven classification.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Permanent is implemented by errors that should short-circuit retries
+// regardless of what Policy.RetryableFunc would otherwise say (decode
+// failure, unsupported format, validation). IsPermanent checks for it.
+type Permanent interface {
+	Permanent() bool
+}
+
+// IsPermanent reports whether err (or something it wraps) implements
+// Permanent and returns true from it.
+func IsPermanent(err error) bool {
+	var p Permanent
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// Policy configures exponential backoff for a workflow step, following the
+// recurrence next = min(prev * Multiplier * rand(1-Jitter, 1+Jitter),
+// MaxBackoff).
+type Policy struct {
+	MaxAttempts    int // total attempts including the first; 0 or 1 disables retries
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64 // 0..1, randomizes each backoff by +/- Jitter
+
+	// RetryableFunc classifies an error as worth retrying. A nil err or an
+	// error implementing Permanent with Permanent() true is never retried
+	// regardless of what RetryableFunc returns. A nil RetryableFunc treats
+	// every other error as retryable.
+	RetryableFunc func(error) bool
+}
+
+// DefaultPolicy returns a conservative policy suitable for the I/O steps of
+// a workflow (content existence checks, downloads, derived-content writes).
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2.0,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the delay before the given zero-based retry attempt
+// (attempt 0 is the delay before the second overall try).
+func (p Policy) Backoff(attempt int) time.Duration {
+	interval := float64(p.InitialBackoff)
+	for i := 0; i < attempt; i++ {
+		interval *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && interval > float64(p.MaxBackoff) {
+		interval = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delta := interval * p.Jitter
+		interval += (rand.Float64()*2 - 1) * delta
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
+
+func (p Policy) retryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+	if p.RetryableFunc == nil {
+		return true
+	}
+	return p.RetryableFunc(err)
+}
+
+// Do runs fn, retrying per p while its error is retryable, and stops early
+// if ctx is cancelled (which also covers a DBOS-initiated shutdown, since
+// DBOSContext implements context.Context) or the next backoff wouldn't fit
+// before ctx's deadline. onRetry, if non-nil, is called with the one-based
+// attempt number that just failed and its error before each backoff sleep,
+// so the caller can log "[runID] attempt N failed: err" with its own run
+// ID. Do returns the number of attempts made alongside the final error (nil
+// on success).
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error, onRetry func(attempt int, err error)) (attempts int, err error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return attempt - 1, ctx.Err()
+		}
+
+		err = fn(ctx)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if attempt == maxAttempts || !p.retryable(err) {
+			return attempt, err
+		}
+
+		delay := p.Backoff(attempt - 1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return attempt, err
+		}
+
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+
+		if !sleepOrDone(ctx, delay) {
+			return attempt, ctx.Err()
+		}
+	}
+
+	return maxAttempts, err
+}
+
+// sleepOrDone waits for d, returning false early if ctx is cancelled first.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -8,12 +8,79 @@ type ProcessRequest struct {
 	Job         string            `json:"job"` // thumbnail, ocr, object_detection
 	Versions    map[string]int    `json:"versions"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// IdempotencyKey, if set, identifies this exact submission for dedupe
+	// purposes (e.g. a hash of the request body) instead of ContentID. Set
+	// it when retrying an enqueue so the retry is recognized as the same
+	// submission even if ContentID alone wouldn't distinguish it.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Renditions, if set, fans a single ThumbnailWorkflow run out into
+	// multiple named outputs (e.g. a small WebP thumb alongside a larger
+	// JPEG preview) decoded from the source image once. When empty,
+	// ThumbnailWorkflow falls back to its single Metadata["width"]/
+	// ["height"] JPEG rendition.
+	Renditions []Rendition `json:"renditions,omitempty"`
+}
+
+// Rendition describes one output of a fan-out ThumbnailWorkflow run: a
+// named, independently sized and formatted derivative of the same decoded
+// source image.
+type Rendition struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Format  string `json:"format"`            // jpeg, png, webp, avif
+	Quality int    `json:"quality,omitempty"` // defaults to 80 if zero
+	Fit     string `json:"fit,omitempty"`     // "fit" (default, preserve aspect ratio) or "resize" (exact dimensions)
 }
 
 // ProcessResponse represents the response from triggering processing
 type ProcessResponse struct {
 	RunID           string `json:"run_id"`
 	DedupeSeenCount int    `json:"dedupe_seen_count"`
+
+	// Suppressed is true when this submission was suppressed under the
+	// dedupe tracker's SuppressionPolicy instead of enqueueing a new
+	// workflow run (see dedupe.Tracker.RecordOrSuppress).
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// ReusedRunID is true when RunID identifies a prior run reused because
+	// it already completed successfully within the suppression window,
+	// rather than a freshly enqueued one.
+	ReusedRunID bool `json:"reused_run_id,omitempty"`
+}
+
+// BatchProcessRequest is the payload for POST /v1/process/batch: a set of
+// ProcessRequest items submitted together.
+type BatchProcessRequest struct {
+	Items []ProcessRequest `json:"items"`
+
+	// IdempotencyKey, if set, is combined with each item's index to derive
+	// a per-item idempotency key for items that don't supply their own.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// BatchItemResult is the per-item outcome of a batch submission.
+type BatchItemResult struct {
+	ContentID string `json:"content_id"`
+	RunID     string `json:"run_id,omitempty"`
+	Deduped   bool   `json:"deduped"`
+	Error     string `json:"error,omitempty"`
+
+	// DedupeSeenCount is this item's own seen count from the batch's atomic
+	// dedupe recording (see dedupe.Tracker.RecordBatch), as opposed to the
+	// whole-batch total carried by BatchProcessResponse.DedupeSeenCount.
+	DedupeSeenCount int `json:"dedupe_seen_count"`
+
+	// Status is "enqueued" or "failed", mirroring Error's presence.
+	Status string `json:"status"`
+}
+
+// BatchProcessResponse is the response from a batch submission.
+type BatchProcessResponse struct {
+	Items           []BatchItemResult `json:"items"`
+	DedupeSeenCount int               `json:"dedupe_seen_count"`
 }
 
 // JobType constants
@@ -0,0 +1,303 @@
+// Package retry provides a shared exponential-backoff policy, error
+// classification, and attempt metrics for the storage and service clients
+// used across the pipeline (internal/storage's FilesystemStorage,
+// DerivedWriter, and HTTP variants). It exists so every caller retries
+// transient failures the same way instead of reimplementing backoff math
+// and drifting in what counts as retryable.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Policy configures exponential backoff: initial interval, max interval, a
+// multiplier, max elapsed time, and jitter, following the standard
+// recurrence next = min(prev * multiplier * rand(1-jitter, 1+jitter),
+// maxInterval), capped overall by MaxElapsedTime.
+type Policy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+	Multiplier      float64
+	Jitter          float64 // 0..1, randomizes each interval by +/- Jitter
+	MaxAttempts     int     // total attempts including the first; 0 or 1 disables retries
+}
+
+// DefaultPolicy returns a conservative policy suitable for most
+// simple-content HTTP API calls and filesystem operations.
+func DefaultPolicy() Policy {
+	return Policy{
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		MaxElapsedTime:  30 * time.Second,
+		Multiplier:      2.0,
+		Jitter:          0.2,
+		MaxAttempts:     3,
+	}
+}
+
+// PolicyFromEnv overlays DefaultPolicy with STORAGE_RETRY_* environment
+// variables, leaving any unset or unparsable value at its default:
+//
+//	STORAGE_RETRY_INITIAL_INTERVAL  duration, e.g. "200ms"
+//	STORAGE_RETRY_MAX_INTERVAL      duration, e.g. "5s"
+//	STORAGE_RETRY_MAX_ELAPSED       duration, e.g. "30s"
+//	STORAGE_RETRY_MULTIPLIER        float, e.g. "2.0"
+//	STORAGE_RETRY_JITTER            float in 0..1, e.g. "0.2"
+//	STORAGE_RETRY_MAX_ATTEMPTS      int, e.g. "3"
+func PolicyFromEnv() Policy {
+	p := DefaultPolicy()
+	if v, ok := envDuration("STORAGE_RETRY_INITIAL_INTERVAL"); ok {
+		p.InitialInterval = v
+	}
+	if v, ok := envDuration("STORAGE_RETRY_MAX_INTERVAL"); ok {
+		p.MaxInterval = v
+	}
+	if v, ok := envDuration("STORAGE_RETRY_MAX_ELAPSED"); ok {
+		p.MaxElapsedTime = v
+	}
+	if v, ok := envFloat("STORAGE_RETRY_MULTIPLIER"); ok {
+		p.Multiplier = v
+	}
+	if v, ok := envFloat("STORAGE_RETRY_JITTER"); ok {
+		p.Jitter = v
+	}
+	if v, ok := envInt("STORAGE_RETRY_MAX_ATTEMPTS"); ok {
+		p.MaxAttempts = v
+	}
+	return p
+}
+
+func envDuration(key string) (time.Duration, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+func envFloat(key string) (float64, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+func envInt(key string) (int, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Backoff returns the delay before the given zero-based retry attempt
+// (attempt 0 is the delay before the second overall try).
+func (p Policy) Backoff(attempt int) time.Duration {
+	interval := float64(p.InitialInterval)
+	for i := 0; i < attempt; i++ {
+		interval *= p.Multiplier
+	}
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		delta := interval * p.Jitter
+		interval += (rand.Float64()*2 - 1) * delta
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
+
+// ExceedsElapsed reports whether MaxElapsedTime has passed since start. A
+// zero MaxElapsedTime means "no limit".
+func (p Policy) ExceedsElapsed(start time.Time) bool {
+	return p.MaxElapsedTime > 0 && time.Since(start) >= p.MaxElapsedTime
+}
+
+// Outcome classifies an error as worth retrying or not.
+type Outcome int
+
+const (
+	// Unknown is returned for a nil error; callers should treat it as
+	// success and stop retrying.
+	Unknown Outcome = iota
+	// Retryable errors are transient: the same call is likely to succeed
+	// on a later attempt (network timeouts, 5xx/429, a momentary EAGAIN).
+	Retryable
+	// Terminal errors will never succeed on retry (bad input, 4xx other
+	// than 429, file not found) and should be returned immediately.
+	Terminal
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Retryable:
+		return "retryable"
+	case Terminal:
+		return "terminal"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyHTTPStatus reports whether an HTTP response status is worth
+// retrying: 429 and 5xx are treated as transient backend trouble; any
+// other 4xx is a terminal caller error.
+func ClassifyHTTPStatus(status int) Outcome {
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return Retryable
+	}
+	if status >= http.StatusBadRequest {
+		return Terminal
+	}
+	return Unknown
+}
+
+// ClassifyError classifies a non-HTTP error as retryable or terminal:
+// network timeouts, a transient os.PathError wrapping EAGAIN, and
+// context.DeadlineExceeded (while the parent ctx still has budget) are
+// retryable; invalid input, file-not-found, and an exhausted parent
+// context are terminal. ctx may be nil.
+func ClassifyError(ctx context.Context, err error) Outcome {
+	if err == nil {
+		return Unknown
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Retryable
+	}
+
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) && errors.Is(pathErr.Err, syscall.EAGAIN) {
+		return Retryable
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		// The call's own deadline expired, but if the caller's ctx is
+		// still alive there's budget left for another attempt.
+		if ctx != nil && ctx.Err() == nil {
+			return Retryable
+		}
+		return Terminal
+	}
+
+	if os.IsNotExist(err) {
+		return Terminal
+	}
+
+	return Terminal
+}
+
+// Do executes fn, retrying according to p while the returned error
+// classifies as Retryable, and records a pipeline_retry_attempts_total
+// count per attempt labeled by op and outcome ("success", "retry", or
+// "terminal"). op should be a short, stable name such as
+// "filesystem.get_reader" or "derived_writer.put_derived".
+func Do(ctx context.Context, p Policy, op string, fn func(ctx context.Context) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			recordAttempt(ctx, op, AttemptSuccess)
+			return nil
+		}
+		lastErr = err
+
+		last := attempt == maxAttempts-1 || p.ExceedsElapsed(start)
+		if ClassifyError(ctx, err) != Retryable || last {
+			recordAttempt(ctx, op, AttemptTerminal)
+			return err
+		}
+		recordAttempt(ctx, op, AttemptRetry)
+
+		if !sleepOrDone(ctx, p.Backoff(attempt)) {
+			return ctx.Err()
+		}
+	}
+
+	return lastErr
+}
+
+// sleepOrDone waits for d, returning false early if ctx is cancelled first.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// AttemptOutcome labels a single retry attempt for the
+// pipeline_retry_attempts_total metric: what happened to that attempt,
+// as opposed to Outcome, which classifies the error that caused it.
+type AttemptOutcome string
+
+const (
+	AttemptSuccess  AttemptOutcome = "success"
+	AttemptRetry    AttemptOutcome = "retry"
+	AttemptTerminal AttemptOutcome = "terminal"
+)
+
+var meter = otel.Meter("github.com/tendant/simple-content-pipeline/pkg/retry")
+
+var attemptCounter, _ = meter.Int64Counter(
+	"pipeline_retry_attempts_total",
+	metric.WithDescription("Count of retry attempts for storage and service operations, labeled by op and outcome, so operators can see whether backoff is masking real problems."),
+)
+
+// RecordAttempt increments pipeline_retry_attempts_total{op,outcome} by
+// one. Exported so callers that implement their own retry loop (e.g. the
+// HTTP variants' GET and chunked-upload paths) can still feed the shared
+// metric.
+func RecordAttempt(ctx context.Context, op string, outcome AttemptOutcome) {
+	recordAttempt(ctx, op, outcome)
+}
+
+func recordAttempt(ctx context.Context, op string, outcome AttemptOutcome) {
+	attemptCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("op", op),
+		attribute.String("outcome", string(outcome)),
+	))
+}
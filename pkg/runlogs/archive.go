@@ -0,0 +1,111 @@
+package runlogs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrRunNotFound is returned when no log directory exists for a run ID,
+// either because it never ran or its logs have already been swept.
+var ErrRunNotFound = errors.New("run log directory not found")
+
+// RunLogArchiver bundles a run's log directory (as written by RunLogger)
+// into a tar.gz archive, for operators to download a self-contained bundle
+// when debugging a specific run.
+type RunLogArchiver struct {
+	baseDir string
+}
+
+// NewRunLogArchiver creates an archiver that reads from the same baseDir a
+// RunLogger writes to.
+func NewRunLogArchiver(baseDir string) *RunLogArchiver {
+	return &RunLogArchiver{baseDir: baseDir}
+}
+
+// Exists reports whether a log directory exists for runID, so callers can
+// return 404 before committing to response headers.
+func (a *RunLogArchiver) Exists(runID string) bool {
+	info, err := os.Stat(filepath.Join(a.baseDir, runID))
+	return err == nil && info.IsDir()
+}
+
+// WriteArchive streams runID's log directory to w as a gzip-compressed tar
+// archive (run.jsonl, steps/<step>.jsonl, and any other files written
+// alongside them), one file at a time, so large runs don't need to be
+// buffered in memory.
+func (a *RunLogArchiver) WriteArchive(w io.Writer, runID string) error {
+	dir := filepath.Join(a.baseDir, runID)
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrRunNotFound
+		}
+		return fmt.Errorf("failed to stat run log dir: %w", err)
+	}
+	if !info.IsDir() {
+		return ErrRunNotFound
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to compute relative path for %q: %w", path, err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %q: %w", path, err)
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat %q: %w", path, err)
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return fmt.Errorf("failed to build tar header for %q: %w", path, err)
+		}
+		hdr.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write tar header for %q: %w", path, err)
+		}
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("failed to write tar entry for %q: %w", path, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		tw.Close()
+		gz.Close()
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Package runlogs persists per-run log entries to disk as JSONL files, so a
+// completed or in-flight workflow run's logs can be bundled into a
+// self-contained archive for download (see RunLogArchiver) instead of only
+// living in the worker's own stdout.
+package runlogs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Config configures TTL-based expiry and the background sweeper for a
+// RunLogger.
+type Config struct {
+	// TTL is how long a run's log directory is kept after its last write
+	// before the sweeper purges it. Zero disables TTL-based expiry.
+	TTL time.Duration
+
+	// SweepInterval is how often StartSweeper checks for expired run
+	// directories. Zero disables the background sweeper even if TTL is set.
+	SweepInterval time.Duration
+}
+
+// Entry is a single structured log record for a run, optionally scoped to
+// one step of that run.
+type Entry struct {
+	RunID   string    `json:"run_id"`
+	Step    string    `json:"step,omitempty"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	TS      time.Time `json:"ts"`
+}
+
+// RunLogger tees per-run log entries into a JSONL file under baseDir,
+// keyed by RunID: <baseDir>/<runID>/run.jsonl holds every entry for the
+// run, and <baseDir>/<runID>/steps/<step>.jsonl holds the subset scoped to
+// that step, so RunLogArchiver can present both a flat and a per-step view.
+type RunLogger struct {
+	baseDir string
+	cfg     Config
+	mu      sync.Mutex
+}
+
+// NewRunLogger creates a RunLogger rooted at baseDir, creating it if
+// necessary.
+func NewRunLogger(baseDir string, cfg Config) (*RunLogger, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run log base dir %q: %w", baseDir, err)
+	}
+	return &RunLogger{baseDir: baseDir, cfg: cfg}, nil
+}
+
+func (l *RunLogger) runDir(runID string) string {
+	return filepath.Join(l.baseDir, runID)
+}
+
+// Record appends entry to its run's run.jsonl, and to its step's JSONL file
+// if entry.Step is set.
+func (l *RunLogger) Record(entry Entry) error {
+	if entry.RunID == "" {
+		return errors.New("run id is required")
+	}
+	if entry.TS.IsZero() {
+		entry.TS = time.Now()
+	}
+
+	dir := l.runDir(entry.RunID)
+	if err := os.MkdirAll(filepath.Join(dir, "steps"), 0o755); err != nil {
+		return fmt.Errorf("failed to create run log dir: %w", err)
+	}
+
+	if err := l.appendJSONL(filepath.Join(dir, "run.jsonl"), entry); err != nil {
+		return err
+	}
+
+	if entry.Step != "" {
+		if err := l.appendJSONL(filepath.Join(dir, "steps", entry.Step+".jsonl"), entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *RunLogger) appendJSONL(path string, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open run log file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode run log entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write run log entry: %w", err)
+	}
+	return nil
+}
+
+// StartSweeper launches a background goroutine that removes run log
+// directories whose most recent write is older than cfg.TTL, every
+// cfg.SweepInterval, until ctx is cancelled. It is a no-op if TTL or
+// SweepInterval is zero.
+func (l *RunLogger) StartSweeper(ctx context.Context) {
+	if l.cfg.TTL <= 0 || l.cfg.SweepInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(l.cfg.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.purgeBefore(time.Now().Add(-l.cfg.TTL))
+			}
+		}
+	}()
+}
+
+func (l *RunLogger) purgeBefore(cutoff time.Time) {
+	entries, err := os.ReadDir(l.baseDir)
+	if err != nil {
+		log.Printf("run log sweep failed: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		// A run directory's own mtime doesn't change when an existing file
+		// in it is appended to, so take the latest mtime of anything inside.
+		path := filepath.Join(l.baseDir, entry.Name())
+		latest := info.ModTime()
+		filepath.Walk(path, func(_ string, fi os.FileInfo, err error) error {
+			if err == nil && fi.ModTime().After(latest) {
+				latest = fi.ModTime()
+			}
+			return nil
+		})
+		if latest.Before(cutoff) {
+			if err := os.RemoveAll(path); err != nil {
+				log.Printf("run log sweep: failed to remove %s: %v", path, err)
+				continue
+			}
+			log.Printf("run log sweep purged %s (last written before %s)", path, cutoff.Format(time.RFC3339))
+		}
+	}
+}
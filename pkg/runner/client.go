@@ -8,8 +8,14 @@ import (
 	"github.com/tendant/simple-content-pipeline/internal/dbosruntime"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/tendant/simple-content-pipeline/pkg/runner")
+
 // Client provides a client-only API for starting workflows without executing them
 // Use this in applications that want to enqueue workflows for workers to execute
 type Client struct {
@@ -47,7 +53,12 @@ func NewClient(cfg Config) (*Client, error) {
 
 // RunThumbnail enqueues a thumbnail generation workflow for workers to execute
 func (c *Client) RunThumbnail(ctx context.Context, contentID string, width, height int) (string, error) {
-	return c.runner.RunAsync(ctx, pipeline.ProcessRequest{
+	ctx, span := tracer.Start(ctx, "runner.Client.RunThumbnail", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+	))
+	defer span.End()
+
+	id, err := c.runner.RunAsync(ctx, pipeline.ProcessRequest{
 		ContentID: contentID,
 		Job:       pipeline.JobThumbnail,
 		Versions: map[string]int{
@@ -58,17 +69,32 @@ func (c *Client) RunThumbnail(ctx context.Context, contentID string, width, heig
 			"height": fmt.Sprintf("%d", height),
 		},
 	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
 }
 
 // RunObjectDetection enqueues an object detection workflow for workers to execute
 func (c *Client) RunObjectDetection(ctx context.Context, contentID string) (string, error) {
-	return c.runner.RunAsync(ctx, pipeline.ProcessRequest{
+	ctx, span := tracer.Start(ctx, "runner.Client.RunObjectDetection", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+	))
+	defer span.End()
+
+	id, err := c.runner.RunAsync(ctx, pipeline.ProcessRequest{
 		ContentID: contentID,
 		Job:       "object_detection",
 		Versions: map[string]int{
 			"object_detection": 1,
 		},
 	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
 }
 
 // Shutdown gracefully shuts down the client
@@ -6,11 +6,19 @@ import (
 	"time"
 
 	"github.com/tendant/simple-content-pipeline/internal/dbosruntime"
+	"github.com/tendant/simple-content-pipeline/internal/detection"
+	"github.com/tendant/simple-content-pipeline/internal/ocr"
 	"github.com/tendant/simple-content-pipeline/internal/storage"
 	"github.com/tendant/simple-content-pipeline/internal/workflows"
 	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// defaultObjectDetectionURL is used when Config.ObjectDetectionURL is unset.
+const defaultObjectDetectionURL = "http://localhost:8500"
+
 // Config holds the configuration for initializing the pipeline runner
 type Config struct {
 	DatabaseURL        string // DBOS PostgreSQL connection string
@@ -19,6 +27,20 @@ type Config struct {
 	Concurrency        int    // Number of concurrent workers
 	ContentAPIURL      string // URL of the content API server
 	ApplicationVersion string // Optional: Override binary hash for version matching
+
+	// OCRLanguage is the Tesseract language pack used by the OCR workflow
+	// (e.g. "eng", "deu"). Defaults to "eng" if unset.
+	OCRLanguage string
+
+	// ObjectDetectionURL is the base URL of the ONNX-backed object
+	// detection service used by the object detection workflow. Defaults to
+	// "http://localhost:8500" if unset.
+	ObjectDetectionURL string
+
+	// Telemetry configures OpenTelemetry tracing/metrics for HTTP calls,
+	// workflow enqueue, and dedupe. Optional; leave nil to use whatever
+	// global providers the host application has already installed.
+	Telemetry *TelemetryConfig
 }
 
 // Runner provides a high-level API for running pipeline workflows via DBOS
@@ -29,6 +51,10 @@ type Runner struct {
 
 // New creates and initializes a new pipeline runner with DBOS integration
 func New(cfg Config) (*Runner, error) {
+	if cfg.Telemetry != nil {
+		cfg.Telemetry.Apply()
+	}
+
 	// Create DBOS runtime
 	dbosRuntime, err := dbosruntime.NewRuntime(context.Background(), dbosruntime.Config{
 		DatabaseURL:        cfg.DatabaseURL,
@@ -52,6 +78,20 @@ func New(cfg Config) (*Runner, error) {
 	thumbnailWorkflow := workflows.NewThumbnailWorkflow(contentReader, derivedWriter)
 	workflowRunner.Register(pipeline.JobThumbnail, thumbnailWorkflow)
 
+	// Register OCR workflow
+	recognizer := ocr.NewTesseractRecognizer(cfg.OCRLanguage)
+	ocrWorkflow := workflows.NewOCRWorkflow(contentReader, derivedWriter, recognizer)
+	workflowRunner.Register(pipeline.JobOCR, ocrWorkflow)
+
+	// Register object detection workflow
+	objectDetectionURL := cfg.ObjectDetectionURL
+	if objectDetectionURL == "" {
+		objectDetectionURL = defaultObjectDetectionURL
+	}
+	detector := detection.NewHTTPDetector(objectDetectionURL)
+	objectDetectionWorkflow := workflows.NewObjectDetectionWorkflow(contentReader, derivedWriter, detector)
+	workflowRunner.Register(pipeline.JobObjectDetection, objectDetectionWorkflow)
+
 	// Launch DBOS (must be after workflow registration)
 	if err := dbosRuntime.Launch(); err != nil {
 		return nil, fmt.Errorf("failed to launch DBOS: %w", err)
@@ -65,7 +105,12 @@ func New(cfg Config) (*Runner, error) {
 
 // RunThumbnail triggers a thumbnail generation workflow
 func (r *Runner) RunThumbnail(ctx context.Context, contentID string, width, height int) (string, error) {
-	return r.runner.RunAsync(ctx, pipeline.ProcessRequest{
+	ctx, span := tracer.Start(ctx, "runner.Runner.RunThumbnail", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+	))
+	defer span.End()
+
+	id, err := r.runner.RunAsync(ctx, pipeline.ProcessRequest{
 		ContentID: contentID,
 		Job:       pipeline.JobThumbnail,
 		Versions: map[string]int{
@@ -76,19 +121,111 @@ func (r *Runner) RunThumbnail(ctx context.Context, contentID string, width, heig
 			"height": fmt.Sprintf("%d", height),
 		},
 	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
 }
 
 // RunObjectDetection triggers an object detection workflow
 func (r *Runner) RunObjectDetection(ctx context.Context, contentID string) (string, error) {
-	// Start workflow by name (language-agnostic)
-	fmt.Println("DEBUG: Using StartWorkflowByName for detect_objects_workflow")
-	return r.runtime.StartWorkflowByName(ctx, "detect_objects_workflow", contentID, nil)
+	ctx, span := tracer.Start(ctx, "runner.Runner.RunObjectDetection", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+	))
+	defer span.End()
+
+	id, err := r.runner.RunAsync(ctx, pipeline.ProcessRequest{
+		ContentID: contentID,
+		Job:       pipeline.JobObjectDetection,
+		Versions: map[string]int{
+			pipeline.DerivedTypeObjectDetections: 1,
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
 }
 
 // RunOCR triggers an OCR workflow
 func (r *Runner) RunOCR(ctx context.Context, contentID string) (string, error) {
-	// Start workflow by name (language-agnostic)
-	return r.runtime.StartWorkflowByName(ctx, "ocr_workflow", contentID, nil)
+	ctx, span := tracer.Start(ctx, "runner.Runner.RunOCR", trace.WithAttributes(
+		attribute.String("content.id", contentID),
+	))
+	defer span.End()
+
+	id, err := r.runner.RunAsync(ctx, pipeline.ProcessRequest{
+		ContentID: contentID,
+		Job:       pipeline.JobOCR,
+		Versions: map[string]int{
+			pipeline.DerivedTypeOCRText: 1,
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
+}
+
+// Status retrieves the status of a workflow run by ID.
+func (r *Runner) Status(ctx context.Context, runID string) (*workflows.WorkflowStatus, error) {
+	ctx, span := tracer.Start(ctx, "runner.Runner.Status", trace.WithAttributes(
+		attribute.String("run.id", runID),
+	))
+	defer span.End()
+
+	status, err := r.runner.GetStatus(ctx, runID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return status, err
+}
+
+// List returns workflow runs matching filter, newest first.
+func (r *Runner) List(ctx context.Context, filter workflows.ListFilter) ([]workflows.WorkflowStatus, error) {
+	ctx, span := tracer.Start(ctx, "runner.Runner.List")
+	defer span.End()
+
+	statuses, err := r.runner.List(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return statuses, err
+}
+
+// Cancel requests cancellation of the workflow run identified by runID.
+func (r *Runner) Cancel(ctx context.Context, runID string) error {
+	ctx, span := tracer.Start(ctx, "runner.Runner.Cancel", trace.WithAttributes(
+		attribute.String("run.id", runID),
+	))
+	defer span.End()
+
+	err := r.runner.Cancel(ctx, runID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// Retry resumes a cancelled or failed workflow run from its last checkpoint.
+func (r *Runner) Retry(ctx context.Context, runID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "runner.Runner.Retry", trace.WithAttributes(
+		attribute.String("run.id", runID),
+	))
+	defer span.End()
+
+	id, err := r.runner.Retry(ctx, runID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return id, err
 }
 
 // Shutdown gracefully shuts down the pipeline runner
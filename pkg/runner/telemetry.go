@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TelemetryConfig configures the OpenTelemetry tracer and meter providers
+// used across the pipeline (HTTP calls, workflow enqueue, dedupe). Leave it
+// nil on Config to use whatever global providers the host application has
+// already installed (or the no-op default).
+//
+// Supply TracerProvider/MeterProvider directly to plug in an existing SDK
+// (e.g. one wired to an OTLP or Zipkin exporter). If either is left nil,
+// Apply constructs a bare SDK provider for it with no exporter attached,
+// which is enough to exercise context propagation (e.g. the trace_id
+// injected into WorkflowInput.Metadata) without sending data anywhere.
+type TelemetryConfig struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// Apply installs the configured providers as the global OpenTelemetry
+// providers. All instrumented packages (internal/storage, internal/dedupe,
+// internal/dbosruntime, pkg/runner) resolve their tracer/meter lazily via
+// the global otel package, so this only needs to run once, before
+// workflows are registered.
+func (c TelemetryConfig) Apply() {
+	tp := c.TracerProvider
+	if tp == nil {
+		tp = sdktrace.NewTracerProvider()
+	}
+	otel.SetTracerProvider(tp)
+
+	mp := c.MeterProvider
+	if mp == nil {
+		mp = sdkmetric.NewMeterProvider()
+	}
+	otel.SetMeterProvider(mp)
+}
@@ -0,0 +1,219 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+	"github.com/tendant/simple-content-pipeline/internal/workflows"
+	"github.com/tendant/simple-content-pipeline/pkg/pipeline"
+)
+
+// WorkflowStep describes a single recorded step of a workflow execution, as
+// reported by the DBOS step history.
+type WorkflowStep struct {
+	StepID      int
+	StepName    string
+	Output      interface{}
+	Error       error
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// WorkflowSnapshot is a point-in-time view of a workflow run: its input,
+// current status, step history, and output (which carries any derived
+// artifacts already written by the workflow).
+type WorkflowSnapshot struct {
+	WorkflowID string
+	Status     workflows.WorkflowStatus
+	Input      interface{}
+	Steps      []WorkflowStep
+}
+
+// WorkflowFilter narrows the workflows returned by Client.List.
+type WorkflowFilter struct {
+	// Pipeline restricts results to a single job type (e.g. "thumbnail").
+	// Matched against the enqueued pipeline.ProcessRequest.Job field.
+	Pipeline string
+
+	// Status restricts results to one workflow state: "pending", "running",
+	// "succeeded", "failed", or "cancelled" (see workflows.WorkflowStatus.State).
+	Status string
+
+	// ContentID restricts results to workflows processing a single content ID.
+	ContentID string
+
+	// CreatedAfter/CreatedBefore bound the workflow creation time. Zero
+	// values leave that bound unset.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// Limit caps the number of results. 0 means the DBOS default.
+	Limit int
+}
+
+// Wait blocks until the workflow identified by workflowUUID reaches a
+// terminal state and returns its result. It relies on DBOS's own
+// notification channel, polling the workflow_status table as a fallback,
+// so no long-lived connection is required.
+func (c *Client) Wait(ctx context.Context, workflowUUID string) (*workflows.WorkflowResult, error) {
+	handle, err := dbos.RetrieveWorkflow[*workflows.WorkflowResult](c.runtime.Context(), workflowUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve workflow %s: %w", workflowUUID, err)
+	}
+
+	result, err := handle.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for workflow %s: %w", workflowUUID, err)
+	}
+
+	return result, nil
+}
+
+// Cancel requests cancellation of the workflow identified by workflowUUID.
+// DBOS workers observe the cancellation and stop the workflow at its next
+// checkpoint; Cancel does not block until that happens.
+func (c *Client) Cancel(ctx context.Context, workflowUUID string) error {
+	if err := dbos.CancelWorkflow(c.runtime.Context(), workflowUUID); err != nil {
+		return fmt.Errorf("failed to cancel workflow %s: %w", workflowUUID, err)
+	}
+	return nil
+}
+
+// Snapshot returns the input, current status, and step history of the
+// workflow identified by workflowUUID. Any derived outputs the workflow has
+// already written are available on Status.Result.Outputs.
+func (c *Client) Snapshot(ctx context.Context, workflowUUID string) (*WorkflowSnapshot, error) {
+	status, err := c.runner.GetStatus(ctx, workflowUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status for workflow %s: %w", workflowUUID, err)
+	}
+
+	handle, err := dbos.RetrieveWorkflow[any](c.runtime.Context(), workflowUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve workflow %s: %w", workflowUUID, err)
+	}
+	dbosStatus, err := handle.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow status for %s: %w", workflowUUID, err)
+	}
+
+	steps, err := dbos.GetWorkflowSteps(c.runtime.Context(), workflowUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow steps for %s: %w", workflowUUID, err)
+	}
+
+	workflowSteps := make([]WorkflowStep, 0, len(steps))
+	for _, s := range steps {
+		workflowSteps = append(workflowSteps, WorkflowStep{
+			StepID:      s.StepID,
+			StepName:    s.StepName,
+			Output:      s.Output,
+			Error:       s.Error,
+			StartedAt:   s.StartedAt,
+			CompletedAt: s.CompletedAt,
+		})
+	}
+
+	return &WorkflowSnapshot{
+		WorkflowID: workflowUUID,
+		Status:     *status,
+		Input:      dbosStatus.Input,
+		Steps:      workflowSteps,
+	}, nil
+}
+
+// List returns workflows matching filter, newest first. Status, time-range,
+// and limit filters are pushed down to DBOS; Pipeline and ContentID are
+// applied client-side since they live inside the enqueued
+// pipeline.ProcessRequest rather than in DBOS's own workflow metadata.
+func (c *Client) List(ctx context.Context, filter WorkflowFilter) ([]workflows.WorkflowStatus, error) {
+	opts := []dbos.ListWorkflowsOption{dbos.WithSortDesc(), dbos.WithLoadInput(true)}
+
+	if statuses := dbosStatusesForState(filter.Status); len(statuses) > 0 {
+		opts = append(opts, dbos.WithStatus(statuses))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		opts = append(opts, dbos.WithStartTime(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		opts = append(opts, dbos.WithEndTime(filter.CreatedBefore))
+	}
+	if filter.Limit > 0 {
+		opts = append(opts, dbos.WithLimit(filter.Limit))
+	}
+
+	statuses, err := dbos.ListWorkflows(c.runtime.Context(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	if filter.Pipeline == "" && filter.ContentID == "" {
+		return toWorkflowStatuses(statuses), nil
+	}
+
+	filtered := make([]dbos.WorkflowStatus, 0, len(statuses))
+	for _, s := range statuses {
+		req, ok := s.Input.(pipeline.ProcessRequest)
+		if !ok {
+			continue
+		}
+		if filter.Pipeline != "" && req.Job != filter.Pipeline {
+			continue
+		}
+		if filter.ContentID != "" && req.ContentID != filter.ContentID {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+
+	return toWorkflowStatuses(filtered), nil
+}
+
+// dbosStatusesForState maps a workflows.WorkflowStatus.State value to the
+// underlying DBOS statuses that produce it (see workflows.MapDBOSStatus).
+func dbosStatusesForState(state string) []dbos.WorkflowStatusType {
+	switch state {
+	case "pending":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusPending, dbos.WorkflowStatusEnqueued}
+	case "succeeded":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusSuccess}
+	case "failed":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusError, dbos.WorkflowStatusMaxRecoveryAttemptsExceeded}
+	case "cancelled":
+		return []dbos.WorkflowStatusType{dbos.WorkflowStatusCancelled}
+	default:
+		return nil
+	}
+}
+
+// toWorkflowStatuses converts DBOS workflow statuses to our public status
+// format, matching the conversion GetStatus already does for a single run.
+func toWorkflowStatuses(statuses []dbos.WorkflowStatus) []workflows.WorkflowStatus {
+	out := make([]workflows.WorkflowStatus, 0, len(statuses))
+	for _, s := range statuses {
+		state := workflows.MapDBOSStatus(string(s.Status))
+
+		var finishedAt *time.Time
+		if state == "succeeded" || state == "failed" {
+			updatedAt := s.UpdatedAt
+			finishedAt = &updatedAt
+		}
+
+		var result *workflows.WorkflowResult
+		if r, ok := s.Output.(*workflows.WorkflowResult); ok {
+			result = r
+		}
+
+		out = append(out, workflows.WorkflowStatus{
+			RunID:      s.ID,
+			State:      state,
+			StartedAt:  s.CreatedAt,
+			FinishedAt: finishedAt,
+			Result:     result,
+			Error:      s.Error,
+		})
+	}
+	return out
+}